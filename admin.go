@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"finalproject/core"
+	"finalproject/helpers"
+	"finalproject/service"
+)
+
+// createAdmin creates an admin account, or promotes the existing account with that email.
+// The password is read from ADMIN_PASSWORD when -password is not given, to keep it out of shell history.
+func createAdmin(ctx context.Context, app *app, args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the admin account")
+	username := flags.String("username", "admin", "username used when the account is created")
+	password := flags.String("password", os.Getenv("ADMIN_PASSWORD"), "password used when the account is created")
+	age := flags.Int("age", 18, "age used when the account is created")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return usageErrorf("-email is required")
+	}
+
+	// Only the repositories and the hasher are needed; app.services would also create a signing
+	// key and connect the mailer
+	passwords, err := helpers.NewPasswords(app.cfg.Passwords.Hasher)
+	if err != nil {
+		return err
+	}
+	users := service.New(app.repositories(), service.Dependencies{Passwords: passwords}).Users
+
+	created, err := users.BootstrapAdmin(ctx, core.User{
+		Email:    *email,
+		Username: *username,
+		Password: *password,
+		Age:      *age,
+	})
+	if err != nil {
+		return err
+	}
+
+	if created {
+		fmt.Printf("Created admin %s\n", *email)
+	} else {
+		fmt.Printf("Promoted %s to admin\n", *email)
+	}
+	return nil
+}
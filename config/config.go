@@ -0,0 +1,424 @@
+// Package config loads the settings of mygram once at startup. Later sources override earlier ones:
+// built-in defaults, an optional YAML file, a .env file, the process environment and finally a few
+// command-line flags. Every field names its YAML key and environment variable in its tags.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Secret is a string that never appears in logs: it formats as "[REDACTED]" unless empty.
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "[REDACTED]"
+}
+
+// GoString keeps %#v from printing the value.
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+// MarshalYAML keeps secrets out of dumped configuration.
+func (s Secret) MarshalYAML() (any, error) {
+	return s.String(), nil
+}
+
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Passwords PasswordsConfig `yaml:"passwords"`
+	Mail      MailConfig      `yaml:"mail"`
+	App       AppConfig       `yaml:"app"`
+	Lockout   LockoutConfig   `yaml:"lockout"`
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+}
+
+type ServerConfig struct {
+	Port            int           `yaml:"port" env:"PORT"`
+	MetricsPort     int           `yaml:"metricsPort" env:"METRICS_PORT"`         // Admin listener for /metrics, 0 disables it
+	DrainDelay      time.Duration `yaml:"drainDelay" env:"DRAIN_DELAY"`           // How long /readyz fails before the listener closes
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"` // How long in-flight requests may drain on shutdown
+	// TrustedProxies lists, comma-separated, the IPs or CIDRs of reverse proxies whose X-Forwarded-For
+	// header is believed. Empty trusts none, so the client IP is always the peer address.
+	TrustedProxies string `yaml:"trustedProxies" env:"TRUSTED_PROXIES"`
+}
+
+// Proxies returns the entries of TrustedProxies.
+func (c ServerConfig) Proxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(c.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// DatabaseConfig accepts either a DATABASE_URL or the individual PG* settings; the URL wins when both are set.
+type DatabaseConfig struct {
+	URL             Secret        `yaml:"url" env:"DATABASE_URL"`
+	Host            string        `yaml:"host" env:"PGHOST"`
+	Port            int           `yaml:"port" env:"PGPORT"`
+	User            string        `yaml:"user" env:"PGUSER"`
+	Password        Secret        `yaml:"password" env:"PGPASS"`
+	Name            string        `yaml:"name" env:"PGDBNAME"`
+	SSLMode         string        `yaml:"sslMode" env:"PGSSLMODE"`         // disable, allow, prefer, require, verify-ca or verify-full
+	SSLRootCert     string        `yaml:"sslRootCert" env:"PGSSLROOTCERT"` // CA bundle for verify-ca and verify-full
+	MaxOpenConns    int           `yaml:"maxOpenConns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"maxIdleConns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime" env:"DB_CONN_MAX_LIFETIME"`
+	AutoMigrate     bool          `yaml:"autoMigrate" env:"AUTO_MIGRATE"`
+}
+
+// DSN returns the connection string for the database.
+func (c DatabaseConfig) DSN() string {
+	if c.URL != "" {
+		return string(c.URL)
+	}
+
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.User, string(c.Password)),
+		Host:   c.Host + ":" + strconv.Itoa(c.Port),
+		Path:   "/" + c.Name,
+	}
+	query := url.Values{"sslmode": {c.SSLMode}}
+	if c.SSLRootCert != "" {
+		query.Set("sslrootcert", c.SSLRootCert)
+	}
+	dsn.RawQuery = query.Encode()
+	return dsn.String()
+}
+
+type JWTConfig struct {
+	Algorithm        string        `yaml:"algorithm" env:"JWT_ALGORITHM"` // RS256 or EdDSA
+	KeysDir          string        `yaml:"keysDir" env:"JWT_KEYS_DIR"`    // Empty keeps keys in memory
+	RotationInterval time.Duration `yaml:"rotationInterval" env:"JWT_ROTATION_INTERVAL"`
+}
+
+type PasswordsConfig struct {
+	Hasher string `yaml:"hasher" env:"PASSWORD_HASHER"` // argon2id or bcrypt
+}
+
+type MailConfig struct {
+	Driver       string `yaml:"driver" env:"MAILER"` // file or smtp
+	Dir          string `yaml:"dir" env:"MAIL_DIR"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+	SMTPHost     string `yaml:"smtpHost" env:"SMTP_HOST"`
+	SMTPPort     int    `yaml:"smtpPort" env:"SMTP_PORT"`
+	SMTPUsername string `yaml:"smtpUsername" env:"SMTP_USERNAME"`
+	SMTPPassword Secret `yaml:"smtpPassword" env:"SMTP_PASSWORD"`
+}
+
+type AppConfig struct {
+	URL                           string `yaml:"url" env:"APP_URL"`
+	RequireVerifiedEmailForPhotos bool   `yaml:"requireVerifiedEmailForPhotos" env:"REQUIRE_VERIFIED_EMAIL_FOR_PHOTOS"`
+	DeletePolicy                  string `yaml:"deletePolicy" env:"DELETE_POLICY"` // cascade or restrict
+}
+
+// LockoutConfig tunes how failed logins and wrong two-factor codes are slowed down and locked out.
+type LockoutConfig struct {
+	Window           time.Duration `yaml:"window" env:"LOCKOUT_WINDOW"`              // How long a failure counts
+	FreeAttempts     int           `yaml:"freeAttempts" env:"LOCKOUT_FREE_ATTEMPTS"` // Failures allowed before delays start
+	BaseDelay        time.Duration `yaml:"baseDelay" env:"LOCKOUT_BASE_DELAY"`       // First delay, doubling with each failure
+	MaxDelay         time.Duration `yaml:"maxDelay" env:"LOCKOUT_MAX_DELAY"`
+	AccountThreshold int           `yaml:"accountThreshold" env:"LOCKOUT_ACCOUNT_THRESHOLD"` // Failures that lock an account
+	IPThreshold      int           `yaml:"ipThreshold" env:"LOCKOUT_IP_THRESHOLD"`           // Failures that lock a client IP
+	Duration         time.Duration `yaml:"duration" env:"LOCKOUT_DURATION"`
+	ResetsPerAddress int           `yaml:"resetsPerAddress" env:"LOCKOUT_RESETS_PER_ADDRESS"` // Password reset requests per window, and verification emails alike
+	ResetsPerIP      int           `yaml:"resetsPerIP" env:"LOCKOUT_RESETS_PER_IP"`
+}
+
+type LogConfig struct {
+	Level      string        `yaml:"level" env:"LOG_LEVEL"`            // debug, info, warn or error
+	Format     string        `yaml:"format" env:"LOG_FORMAT"`          // json or text
+	SampleRate float64       `yaml:"sampleRate" env:"LOG_SAMPLE_RATE"` // Share of successful requests logged; failures are always logged
+	SlowQuery  time.Duration `yaml:"slowQuery" env:"LOG_SLOW_QUERY"`   // Queries slower than this are logged as warnings
+	Queries    bool          `yaml:"queries" env:"LOG_QUERIES"`        // Log every query at debug level
+}
+
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER"`                // none, stdout, memory or otlp
+	OTLPEndpoint string  `yaml:"otlpEndpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"` // e.g. http://localhost:4318
+	ServiceName  string  `yaml:"serviceName" env:"OTEL_SERVICE_NAME"`
+	SampleRatio  float64 `yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO"` // Share of new traces recorded; incoming sampled traces are always followed
+}
+
+// Default returns the built-in defaults, suitable for local development.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{Port: 8080, MetricsPort: 9090, DrainDelay: 5 * time.Second, ShutdownTimeout: 15 * time.Second},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "mygram",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		JWT: JWTConfig{
+			Algorithm:        "RS256",
+			KeysDir:          "keys",
+			RotationInterval: 30 * 24 * time.Hour,
+		},
+		Passwords: PasswordsConfig{Hasher: "argon2id"},
+		Mail: MailConfig{
+			Driver:   "file",
+			Dir:      "mail",
+			From:     "mygram <no-reply@mygram.local>",
+			SMTPPort: 587,
+		},
+		App: AppConfig{
+			URL:          "http://localhost:8080",
+			DeletePolicy: "cascade",
+		},
+		Lockout: LockoutConfig{
+			Window:           15 * time.Minute,
+			FreeAttempts:     3,
+			BaseDelay:        time.Second,
+			MaxDelay:         30 * time.Second,
+			AccountThreshold: 10,
+			IPThreshold:      50,
+			Duration:         15 * time.Minute,
+			ResetsPerAddress: 3,
+			ResetsPerIP:      20,
+		},
+		Log: LogConfig{
+			Level:      "info",
+			Format:     "json",
+			SampleRate: 1,
+			SlowQuery:  200 * time.Millisecond,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "mygram",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Flags holds the command-line sources: the config file to read and overrides that beat every other source.
+type Flags struct {
+	File        string
+	Port        int
+	DatabaseURL string
+}
+
+// RegisterFlags defines -config, -port and -database-url on fs. CONFIG_FILE is the default of -config.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{}
+	fs.StringVar(&flags.File, "config", os.Getenv("CONFIG_FILE"), "YAML configuration file")
+	fs.IntVar(&flags.Port, "port", 0, "HTTP port, overriding PORT")
+	fs.StringVar(&flags.DatabaseURL, "database-url", "", "database URL, overriding DATABASE_URL")
+	return flags
+}
+
+// Load builds the configuration from the defaults, the YAML file named by flags.File (if any),
+// the .env file in the working directory (when present), the environment and the flag overrides,
+// then validates it.
+func Load(flags Flags) (Config, error) {
+	cfg := Default()
+
+	if flags.File != "" {
+		content, err := os.ReadFile(flags.File)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return Config{}, fmt.Errorf("failed to parse config file %s: %w", flags.File, err)
+		}
+	}
+
+	// godotenv never overrides variables that are already set, so the real environment wins
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Config{}, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	if err := applyEnv(reflect.ValueOf(&cfg).Elem(), os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	if flags.Port != 0 {
+		cfg.Server.Port = flags.Port
+	}
+	if flags.DatabaseURL != "" {
+		cfg.Database.URL = Secret(flags.DatabaseURL)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides every field tagged with env whose variable is set and not empty,
+// so blank entries such as "SMTP_HOST=" in .env keep the earlier value.
+func applyEnv(v reflect.Value, lookup func(string) (string, bool)) error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field, fieldType := v.Field(i), v.Type().Field(i)
+		if fieldType.Type.Kind() == reflect.Struct {
+			if err := applyEnv(field, lookup); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		name := fieldType.Tag.Get("env")
+		value, ok := lookup(name)
+		if name == "" || !ok || value == "" {
+			continue
+		}
+		if err := set(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func set(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == reflect.TypeOf(time.Duration(0)):
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetInt(int64(parsed))
+	case field.Kind() == reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(parsed)
+	case field.Kind() == reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		field.SetBool(parsed)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	oneOf := func(value string, allowed ...string) bool {
+		for _, candidate := range allowed {
+			if value == candidate {
+				return true
+			}
+		}
+		return false
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "PORT must be between 1 and 65535")
+	check(c.Server.MetricsPort >= 0 && c.Server.MetricsPort < 65536, "METRICS_PORT must be between 0 and 65535")
+	check(c.Server.MetricsPort != c.Server.Port, "METRICS_PORT must differ from PORT")
+	check(c.Server.DrainDelay >= 0, "DRAIN_DELAY must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+	for _, proxy := range c.Server.Proxies() {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "TRUSTED_PROXIES entry %q is not an IP or CIDR", proxy)
+	}
+
+	if c.Database.URL != "" {
+		parsed, err := url.Parse(string(c.Database.URL))
+		check(err == nil && (parsed.Scheme == "postgres" || parsed.Scheme == "postgresql"), "DATABASE_URL must be a postgres:// URL")
+	} else {
+		check(c.Database.Host != "", "PGHOST is required")
+		check(c.Database.Port > 0 && c.Database.Port < 65536, "PGPORT must be between 1 and 65535")
+		check(c.Database.User != "", "PGUSER is required")
+		check(c.Database.Name != "", "PGDBNAME is required")
+		check(oneOf(c.Database.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+			"PGSSLMODE must be one of disable, allow, prefer, require, verify-ca or verify-full")
+	}
+	check(c.Database.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	check(c.Database.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+
+	check(oneOf(c.JWT.Algorithm, "RS256", "EdDSA"), "JWT_ALGORITHM must be RS256 or EdDSA")
+	check(c.JWT.RotationInterval > 0, "JWT_ROTATION_INTERVAL must be positive")
+	check(oneOf(c.Passwords.Hasher, "argon2id", "bcrypt"), "PASSWORD_HASHER must be argon2id or bcrypt")
+
+	check(oneOf(c.Mail.Driver, "file", "smtp"), "MAILER must be file or smtp")
+	check(c.Mail.Driver != "smtp" || c.Mail.SMTPHost != "", "SMTP_HOST is required when MAILER is smtp")
+	check(c.Mail.Driver != "file" || c.Mail.Dir != "", "MAIL_DIR is required when MAILER is file")
+	check(c.Mail.SMTPPort > 0 && c.Mail.SMTPPort < 65536, "SMTP_PORT must be between 1 and 65535")
+
+	_, err := url.ParseRequestURI(c.App.URL)
+	check(err == nil, "APP_URL must be an absolute URL")
+	check(oneOf(c.App.DeletePolicy, "cascade", "restrict"), "DELETE_POLICY must be cascade or restrict")
+
+	check(c.Lockout.Window > 0, "LOCKOUT_WINDOW must be positive")
+	check(c.Lockout.FreeAttempts > 0, "LOCKOUT_FREE_ATTEMPTS must be positive")
+	check(c.Lockout.BaseDelay > 0, "LOCKOUT_BASE_DELAY must be positive")
+	check(c.Lockout.MaxDelay >= c.Lockout.BaseDelay, "LOCKOUT_MAX_DELAY must not be shorter than LOCKOUT_BASE_DELAY")
+	check(c.Lockout.AccountThreshold > 0, "LOCKOUT_ACCOUNT_THRESHOLD must be positive")
+	check(c.Lockout.IPThreshold > 0, "LOCKOUT_IP_THRESHOLD must be positive")
+	check(c.Lockout.Duration > 0, "LOCKOUT_DURATION must be positive")
+	check(c.Lockout.ResetsPerAddress > 0, "LOCKOUT_RESETS_PER_ADDRESS must be positive")
+	check(c.Lockout.ResetsPerIP > 0, "LOCKOUT_RESETS_PER_IP must be positive")
+
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"), "LOG_LEVEL must be debug, info, warn or error")
+	check(oneOf(c.Log.Format, "json", "text"), "LOG_FORMAT must be json or text")
+	check(c.Log.SampleRate >= 0 && c.Log.SampleRate <= 1, "LOG_SAMPLE_RATE must be between 0 and 1")
+	check(c.Log.SlowQuery >= 0, "LOG_SLOW_QUERY must not be negative")
+
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "memory", "otlp"), "TRACING_EXPORTER must be none, stdout, memory or otlp")
+	if c.Tracing.OTLPEndpoint != "" {
+		_, err := url.ParseRequestURI(c.Tracing.OTLPEndpoint)
+		check(err == nil, "OTEL_EXPORTER_OTLP_ENDPOINT must be an absolute URL")
+	}
+	check(c.Tracing.ServiceName != "", "OTEL_SERVICE_NAME is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// String renders the configuration as YAML with every Secret redacted, for startup logs.
+func (c Config) String() string {
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
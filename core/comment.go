@@ -1,27 +1,18 @@
 package core
 
-import (
-	"fmt"
-	"time"
-
-	"gorm.io/gorm"
-)
+import "finalproject/validation"
 
 type Comment struct {
-	gorm.Model
-	ID        int64  `json:"id"` // Use int64 for bigint
-	UserID    int64  `json:"userId" gorm:"not null"`
-	PhotoID   int64  `json:"photoId" gorm:"not null"`
-	Message   string `json:"message" gorm:"not null"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Model
+	UserID  int64  `json:"-" gorm:"not null;index"`
+	PhotoID int64  `json:"-" gorm:"not null;index"` // Set by CommentService.Create from the public photo ID
+	Message string `json:"message" gorm:"not null" validate:"required,max=1000"`
+
+	User  *User  `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" validate:"-"`
+	Photo *Photo `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" validate:"-"`
 }
 
+// Validate returns validation.Errors listing every invalid field.
 func (c *Comment) Validate() error {
-	// Message validation
-	if len(c.Message) == 0 {
-		return fmt.Errorf("komentar harus diisi")
-	}
-
-	return nil
+	return validation.Struct(c)
 }
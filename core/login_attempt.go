@@ -0,0 +1,19 @@
+package core
+
+import "time"
+
+// LoginAttempt counts recent failed logins for one key, either an account ("email:...") or a client IP ("ip:...").
+type LoginAttempt struct {
+	ID            int64      `json:"-" gorm:"primaryKey"`
+	Key           string     `json:"key" gorm:"not null;uniqueIndex"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"lastFailureAt"`
+	LockedUntil   *time.Time `json:"lockedUntil"`
+	CreatedAt     time.Time  `json:"-"`
+	UpdatedAt     time.Time  `json:"-"`
+}
+
+// Locked reports whether the key is locked out at now.
+func (a *LoginAttempt) Locked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// Model is embedded by every model exposed through the API. ID is the internal bigint key used for
+// joins and foreign keys and is never serialized; clients only ever see the opaque PublicID.
+type Model struct {
+	ID        int64          `json:"-" gorm:"primaryKey"`
+	PublicID  string         `json:"id" gorm:"type:char(26);not null;uniqueIndex"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// NewPublicID returns a new ULID. ULIDs sort by creation time but, unlike sequential keys,
+// cannot be enumerated.
+func NewPublicID() string {
+	return ulid.MustNew(ulid.Now(), rand.Reader).String()
+}
+
+// ValidPublicID reports whether id is a well-formed public ID.
+func ValidPublicID(id string) bool {
+	_, err := ulid.ParseStrict(id)
+	return err == nil
+}
+
+// BeforeCreate assigns the public ID of new rows.
+func (m *Model) BeforeCreate(*gorm.DB) error {
+	if m.PublicID == "" {
+		m.PublicID = NewPublicID()
+	}
+	return nil
+}
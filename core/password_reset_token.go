@@ -0,0 +1,19 @@
+package core
+
+import "time"
+
+// PasswordResetToken is a single-use credential emailed to a user who forgot their password.
+type PasswordResetToken struct {
+	ID        int64      `json:"-" gorm:"primaryKey"`
+	UserID    int64      `json:"-" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"` // SHA-256 of the emailed token
+	ExpiresAt time.Time  `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+	UpdatedAt time.Time  `json:"-"`
+}
+
+// Active reports whether the token can still be redeemed.
+func (t *PasswordResetToken) Active(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
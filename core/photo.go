@@ -1,32 +1,19 @@
 package core
 
-import (
-	"gorm.io/gorm"
-	"time"
-    "fmt"
-)
+import "finalproject/validation"
 
 type Photo struct {
-    gorm.Model
-    ID          int64  `json:"id"`             // Use int64 for bigint
-    Title       string `json:"title" gorm:"not null"`
-    Caption     string `json:"caption" gorm:"not null"`
-    PhotoURL    string `json:"photoUrl" gorm:"not null;type:text"`
-    UserID      int64  `json:"userId" gorm:"not null"`
-    CreatedAt   time.Time
-    UpdatedAt   time.Time
+	Model
+	Title    string `json:"title" gorm:"not null" validate:"required,max=200"`
+	Caption  string `json:"caption" gorm:"not null" validate:"max=2000"`
+	PhotoURL string `json:"photoUrl" gorm:"not null;type:text" validate:"required,weburl"`
+	UserID   int64  `json:"-" gorm:"not null;index"`
+
+	User     *User     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" validate:"-"`
+	Comments []Comment `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" validate:"-"`
 }
 
+// Validate returns validation.Errors listing every invalid field.
 func (p *Photo) Validate() error {
-    // Title validation
-    if len(p.Title) == 0 {
-        return fmt.Errorf("judul foto harus diisi")
-    }
-
-    // Photo URL validation
-    if len(p.PhotoURL) == 0 {
-        return fmt.Errorf("url foto harus diisi")
-    }
-
-    return nil
-}
\ No newline at end of file
+	return validation.Struct(p)
+}
@@ -0,0 +1,13 @@
+package core
+
+import "time"
+
+// RecoveryCode is a one-time code that replaces a TOTP code when the authenticator is lost.
+type RecoveryCode struct {
+	ID        int64      `json:"-" gorm:"primaryKey"`
+	UserID    int64      `json:"-" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null"` // Password hash (argon2id or bcrypt) of the normalized code
+	UsedAt    *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+	UpdatedAt time.Time  `json:"-"`
+}
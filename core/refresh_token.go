@@ -0,0 +1,24 @@
+package core
+
+import "time"
+
+// RefreshToken is one link in a rotation chain. Every token minted from the same login shares a FamilyID,
+// which is also what users see as a session.
+type RefreshToken struct {
+	ID        int64      `json:"-" gorm:"primaryKey"`
+	UserID    int64      `json:"-" gorm:"not null;index"`
+	FamilyID  string     `json:"-" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"` // SHA-256 of the opaque token, never the token itself
+	UserAgent string     `json:"-"`
+	ClientIP  string     `json:"-"`
+	ExpiresAt time.Time  `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"-"` // Set once the token has been rotated
+	RevokedAt *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+	UpdatedAt time.Time  `json:"-"`
+}
+
+// Active reports whether the token can still be exchanged.
+func (t *RefreshToken) Active(now time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,52 @@
+package core
+
+// Role is the access level of a user.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// Permission is an action beyond managing one's own content.
+type Permission string
+
+const (
+	PermissionDeleteAnyPhoto   Permission = "photos:delete:any"
+	PermissionDeleteAnyComment Permission = "comments:delete:any"
+	PermissionManageUsers      Permission = "users:manage"
+	PermissionUnlockAccounts   Permission = "accounts:unlock"
+)
+
+// rolePermissions is the permission matrix. Every user may manage their own content,
+// so RoleUser has no extra permissions.
+var rolePermissions = map[Role][]Permission{
+	RoleUser: {},
+	RoleModerator: {
+		PermissionDeleteAnyPhoto,
+		PermissionDeleteAnyComment,
+	},
+	RoleAdmin: {
+		PermissionDeleteAnyPhoto,
+		PermissionDeleteAnyComment,
+		PermissionManageUsers,
+		PermissionUnlockAccounts,
+	},
+}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants the permission.
+func (r Role) Can(permission Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
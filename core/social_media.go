@@ -1,32 +1,17 @@
 package core
 
-import (
-	"fmt"
-	"time"
-
-	"gorm.io/gorm"
-)
+import "finalproject/validation"
 
 type SocialMedia struct {
-	gorm.Model
-	ID             int64  `json:"id"` // Use int64 for bigint
-	Name           string `json:"name" gorm:"not null"`
-	SocialMediaURL string `json:"socialMediaUrl" gorm:"not null;type:text"`
-	UserID         int64  `json:"userId" gorm:"not null"`
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	Model
+	Name           string `json:"name" gorm:"not null" validate:"required,max=100"`
+	SocialMediaURL string `json:"socialMediaUrl" gorm:"not null;type:text" validate:"required,weburl"`
+	UserID         int64  `json:"-" gorm:"not null;index"`
+
+	User *User `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" validate:"-"`
 }
 
+// Validate returns validation.Errors listing every invalid field.
 func (s *SocialMedia) Validate() error {
-	// Name validation
-	if len(s.Name) == 0 {
-		return fmt.Errorf("nama media sosial harus diisi")
-	}
-
-	// Social media URL validation
-	if len(s.SocialMediaURL) == 0 {
-		return fmt.Errorf("url media sosial harus diisi")
-	}
-
-	return nil
+	return validation.Struct(s)
 }
@@ -0,0 +1,21 @@
+package core
+
+import "time"
+
+// TwoFactorChallenge is the server-side record of a challenge token handed out by a password login
+// on an account with two-factor authentication. It is redeemed once, or burnt after too many wrong codes.
+type TwoFactorChallenge struct {
+	ID        int64      `json:"-" gorm:"primaryKey"`
+	UserID    int64      `json:"-" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"` // SHA-256 of the jti of the challenge token
+	Failures  int        `json:"-" gorm:"not null;default:0"`
+	ExpiresAt time.Time  `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+	UpdatedAt time.Time  `json:"-"`
+}
+
+// Active reports whether the challenge can still be completed.
+func (c *TwoFactorChallenge) Active(now time.Time) bool {
+	return c.UsedAt == nil && now.Before(c.ExpiresAt)
+}
@@ -1,46 +1,43 @@
 package core
 
 import (
-	"fmt"
-	"regexp"
 	"time"
 
-	"gorm.io/gorm"
+	"finalproject/validation"
 )
 
 type User struct {
-	gorm.Model
-	ID              int64  `json:"id"` // Use int64 for bigint
-	Username        string `json:"username" gorm:"not null;unique"`
-	Email           string `json:"email" gorm:"not null;unique"`
-	Password        string `json:"password" gorm:"not null"`
-	Age             int    `json:"age" gorm:"not null"`
-	ProfileImageURL string `json:"profileImageUrl" gorm:"type:text"`
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	Model
+	Username        string     `json:"username" gorm:"not null;uniqueIndex:idx_users_username,where:deleted_at IS NULL" validate:"required,username"`
+	Email           string     `json:"email" gorm:"not null;uniqueIndex:idx_users_email,where:deleted_at IS NULL" validate:"required,email,max=254"`
+	Password        string     `json:"-" gorm:"not null" validate:"required,min=6"` // Checked before hashing; a stored hash always passes
+	Age             int        `json:"age" gorm:"not null" validate:"required,age"`
+	ProfileImageURL string     `json:"profileImageUrl" gorm:"type:text" validate:"omitempty,weburl"`
+	Role            Role       `json:"role" gorm:"not null;default:user"`
+	Locale          string     `json:"locale" validate:"omitempty,oneof=en id"` // Preferred response language; empty follows Accept-Language
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	PendingEmail    string     `json:"pendingEmail,omitempty"` // New address awaiting confirmation; Email stays active until then
+	TOTPSecret      string     `json:"-"`                      // Set at enrollment, in use once TOTPEnabledAt is set
+	TOTPEnabledAt   *time.Time `json:"totpEnabledAt"`
+	TOTPLastStep    int64      `json:"-"` // Last accepted time step, so a code cannot be replayed
 }
 
-func (u *User) Validate() error {
-	// Email validation
-	emailRegex := regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
-	if !emailRegex.MatchString(u.Email) {
-		return fmt.Errorf("email tidak valid")
-	}
-
-	// Username validation
-	if len(u.Username) == 0 {
-		return fmt.Errorf("username harus diisi")
-	}
+// EmailVerified reports whether the current email address has been confirmed.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
 
-	// Password validation
-	if len(u.Password) < 6 {
-		return fmt.Errorf("password minimal harus memiliki 6 karakter")
-	}
+// TwoFactorEnabled reports whether login requires a TOTP or recovery code.
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
 
-	// Age validation
-	if u.Age == 0 {
-		return fmt.Errorf("usia harus diisi")
-	}
+// Validate returns validation.Errors listing every invalid field.
+func (u *User) Validate() error {
+	return validation.Struct(u)
+}
 
-	return nil
+// ValidatePassword checks the password rules shared by registration and password reset.
+func ValidatePassword(password string) error {
+	return validation.Var("password", password, "required,min=6")
 }
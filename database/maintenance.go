@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tables lists the application tables, parents before children.
+var Tables = []string{
+	"users", "social_media", "photos", "comments",
+	"refresh_tokens", "password_reset_tokens", "two_factor_challenges", "recovery_codes", "login_attempts",
+}
+
+// PurgeResult counts the rows removed, or that would be removed on a dry run, per table.
+type PurgeResult struct {
+	Comments    int64
+	Photos      int64
+	SocialMedia int64
+	Users       int64
+}
+
+// errDryRun rolls back the purge transaction after counting.
+var errDryRun = errors.New("dry run")
+
+// purgeStatements run children first, since the foreign keys restrict deletes. A parent is only purged once
+// no rows reference it, soft-deleted or not; tokens and recovery codes go with their user through ON DELETE CASCADE.
+var purgeStatements = []struct {
+	table string
+	sql   string
+}{
+	{"comments", `DELETE FROM comments WHERE deleted_at < ?`},
+	{"photos", `DELETE FROM photos p WHERE p.deleted_at < ?
+		AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.photo_id = p.id)`},
+	{"social_media", `DELETE FROM social_media WHERE deleted_at < ?`},
+	{"users", `DELETE FROM users u WHERE u.deleted_at < ?
+		AND NOT EXISTS (SELECT 1 FROM photos p WHERE p.user_id = u.id)
+		AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.user_id = u.id)
+		AND NOT EXISTS (SELECT 1 FROM social_media s WHERE s.user_id = u.id)`},
+}
+
+// PurgeDeleted permanently removes rows soft-deleted before cutoff, in one transaction.
+// With dryRun the rows are counted and the transaction rolled back.
+func PurgeDeleted(ctx context.Context, db *gorm.DB, cutoff time.Time, dryRun bool) (PurgeResult, error) {
+	var result PurgeResult
+	counts := map[string]*int64{
+		"comments":     &result.Comments,
+		"photos":       &result.Photos,
+		"social_media": &result.SocialMedia,
+		"users":        &result.Users,
+	}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, statement := range purgeStatements {
+			exec := tx.Exec(statement.sql, cutoff)
+			if exec.Error != nil {
+				return fmt.Errorf("failed to purge %s: %w", statement.table, exec.Error)
+			}
+			*counts[statement.table] = exec.RowsAffected
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return result, err
+}
+
+// ReindexTable rebuilds the indexes of table and refreshes its planner statistics.
+// concurrently avoids blocking writes, at the cost of a slower rebuild that cannot run in a transaction.
+func ReindexTable(ctx context.Context, db *gorm.DB, table string, concurrently bool) error {
+	known := false
+	for _, name := range Tables {
+		known = known || name == table
+	}
+	if !known {
+		return fmt.Errorf("unknown table %q", table)
+	}
+
+	reindex := "REINDEX TABLE "
+	if concurrently {
+		reindex = "REINDEX TABLE CONCURRENTLY "
+	}
+	if err := db.WithContext(ctx).Exec(reindex + table).Error; err != nil {
+		return fmt.Errorf("failed to reindex %s: %w", table, err)
+	}
+	if err := db.WithContext(ctx).Exec("ANALYZE " + table).Error; err != nil {
+		return fmt.Errorf("failed to analyze %s: %w", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+-- Baseline schema. Every statement is idempotent so databases previously built by gorm's
+-- AutoMigrate adopt it without changes.
+
+CREATE TABLE IF NOT EXISTS users (
+    id                bigserial PRIMARY KEY,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    deleted_at        timestamptz,
+    username          text NOT NULL,
+    email             text NOT NULL,
+    password          text NOT NULL,
+    age               bigint NOT NULL,
+    profile_image_url text,
+    role              text NOT NULL DEFAULT 'user',
+    locale            text,
+    email_verified_at timestamptz,
+    pending_email     text,
+    totp_secret       text,
+    totp_enabled_at   timestamptz,
+    totp_last_step    bigint
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS social_media (
+    id               bigserial PRIMARY KEY,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    deleted_at       timestamptz,
+    name             text NOT NULL,
+    social_media_url text NOT NULL,
+    user_id          bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_social_media_user_id ON social_media (user_id);
+CREATE INDEX IF NOT EXISTS idx_social_media_deleted_at ON social_media (deleted_at);
+
+CREATE TABLE IF NOT EXISTS photos (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    title      text NOT NULL,
+    caption    text NOT NULL,
+    photo_url  text NOT NULL,
+    user_id    bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_photos_user_id ON photos (user_id);
+CREATE INDEX IF NOT EXISTS idx_photos_deleted_at ON photos (deleted_at);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id    bigint NOT NULL,
+    photo_id   bigint NOT NULL,
+    message    text NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_comments_user_id ON comments (user_id);
+CREATE INDEX IF NOT EXISTS idx_comments_photo_id ON comments (photo_id);
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments (deleted_at);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    family_id  text NOT NULL,
+    token_hash text NOT NULL,
+    user_agent text,
+    client_ip  text,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    token_hash text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_password_reset_tokens_token_hash ON password_reset_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    code_hash  text NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id              bigserial PRIMARY KEY,
+    "key"           text NOT NULL,
+    failures        bigint NOT NULL DEFAULT 0,
+    last_failure_at timestamptz,
+    locked_until    timestamptz,
+    created_at      timestamptz,
+    updated_at      timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_login_attempts_key ON login_attempts ("key");
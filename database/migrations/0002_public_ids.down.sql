@@ -0,0 +1,4 @@
+ALTER TABLE comments DROP COLUMN IF EXISTS public_id;
+ALTER TABLE photos DROP COLUMN IF EXISTS public_id;
+ALTER TABLE social_media DROP COLUMN IF EXISTS public_id;
+ALTER TABLE users DROP COLUMN IF EXISTS public_id;
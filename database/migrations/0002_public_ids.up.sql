@@ -0,0 +1,22 @@
+-- Opaque public IDs for the models exposed through the API. Existing rows get a random
+-- ULID-shaped ID: a leading 0 and 25 hex digits, all valid Crockford base32.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS public_id char(26);
+UPDATE users SET public_id = '0' || upper(substr(md5(random()::text || id::text), 1, 25)) WHERE public_id IS NULL;
+ALTER TABLE users ALTER COLUMN public_id SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_public_id ON users (public_id);
+
+ALTER TABLE social_media ADD COLUMN IF NOT EXISTS public_id char(26);
+UPDATE social_media SET public_id = '0' || upper(substr(md5(random()::text || id::text), 1, 25)) WHERE public_id IS NULL;
+ALTER TABLE social_media ALTER COLUMN public_id SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_social_media_public_id ON social_media (public_id);
+
+ALTER TABLE photos ADD COLUMN IF NOT EXISTS public_id char(26);
+UPDATE photos SET public_id = '0' || upper(substr(md5(random()::text || id::text), 1, 25)) WHERE public_id IS NULL;
+ALTER TABLE photos ALTER COLUMN public_id SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_photos_public_id ON photos (public_id);
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS public_id char(26);
+UPDATE comments SET public_id = '0' || upper(substr(md5(random()::text || id::text), 1, 25)) WHERE public_id IS NULL;
+ALTER TABLE comments ALTER COLUMN public_id SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_comments_public_id ON comments (public_id);
@@ -0,0 +1,7 @@
+ALTER TABLE recovery_codes DROP CONSTRAINT IF EXISTS fk_recovery_codes_user;
+ALTER TABLE password_reset_tokens DROP CONSTRAINT IF EXISTS fk_password_reset_tokens_user;
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS fk_refresh_tokens_user;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS fk_comments_user;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS fk_comments_photo;
+ALTER TABLE photos DROP CONSTRAINT IF EXISTS fk_photos_user;
+ALTER TABLE social_media DROP CONSTRAINT IF EXISTS fk_social_media_user;
@@ -0,0 +1,36 @@
+-- Referential integrity. Rows pointing at users or photos that never existed (e.g. comments on a
+-- made-up photo ID) could be created before the foreign keys did; they are unreachable, so they
+-- are removed rather than kept as orphans. Constraints AutoMigrate may have created under the same
+-- or gorm's default names are replaced.
+
+DELETE FROM photos WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM comments WHERE photo_id NOT IN (SELECT id FROM photos) OR user_id NOT IN (SELECT id FROM users);
+DELETE FROM social_media WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM refresh_tokens WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM password_reset_tokens WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM recovery_codes WHERE user_id NOT IN (SELECT id FROM users);
+
+ALTER TABLE social_media DROP CONSTRAINT IF EXISTS fk_social_media_user;
+ALTER TABLE social_media ADD CONSTRAINT fk_social_media_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+ALTER TABLE photos DROP CONSTRAINT IF EXISTS fk_photos_user;
+ALTER TABLE photos ADD CONSTRAINT fk_photos_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS fk_photos_comments;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS fk_comments_photo;
+ALTER TABLE comments ADD CONSTRAINT fk_comments_photo
+    FOREIGN KEY (photo_id) REFERENCES photos (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS fk_comments_user;
+ALTER TABLE comments ADD CONSTRAINT fk_comments_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+-- Credentials go with the account when it is hard-deleted
+ALTER TABLE refresh_tokens ADD CONSTRAINT fk_refresh_tokens_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE password_reset_tokens ADD CONSTRAINT fk_password_reset_tokens_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE recovery_codes ADD CONSTRAINT fk_recovery_codes_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
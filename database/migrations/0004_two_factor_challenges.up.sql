@@ -0,0 +1,19 @@
+-- Challenge tokens are single-use: the jti of each one is recorded and consumed on verification,
+-- and wrong codes are counted so a challenge is burnt after a few guesses.
+
+CREATE TABLE IF NOT EXISTS two_factor_challenges (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    token_hash text NOT NULL,
+    failures   bigint NOT NULL DEFAULT 0,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_two_factor_challenges_user_id ON two_factor_challenges (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_two_factor_challenges_token_hash ON two_factor_challenges (token_hash);
+
+ALTER TABLE two_factor_challenges DROP CONSTRAINT IF EXISTS fk_two_factor_challenges_user;
+ALTER TABLE two_factor_challenges ADD CONSTRAINT fk_two_factor_challenges_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
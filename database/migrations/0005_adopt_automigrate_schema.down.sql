@@ -0,0 +1,2 @@
+-- Nothing to undo: the columns belong to the 0001 schema and the foreign keys to 0003, whose own
+-- down scripts remove them.
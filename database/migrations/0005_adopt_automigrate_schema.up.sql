@@ -0,0 +1,22 @@
+-- Completes the adoption of databases previously built by gorm's AutoMigrate. 0001 skips tables
+-- that already exist, so one created before the later user columns is missing them; they are
+-- added here. The credential foreign keys are also re-created with a DROP CONSTRAINT IF EXISTS
+-- guard, so a database where they already exist ends up with the same definitions as a fresh one.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale text;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email text;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret text;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step bigint;
+
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS fk_refresh_tokens_user;
+ALTER TABLE refresh_tokens ADD CONSTRAINT fk_refresh_tokens_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE password_reset_tokens DROP CONSTRAINT IF EXISTS fk_password_reset_tokens_user;
+ALTER TABLE password_reset_tokens ADD CONSTRAINT fk_password_reset_tokens_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE recovery_codes DROP CONSTRAINT IF EXISTS fk_recovery_codes_user;
+ALTER TABLE recovery_codes ADD CONSTRAINT fk_recovery_codes_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
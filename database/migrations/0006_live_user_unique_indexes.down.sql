@@ -0,0 +1,7 @@
+-- Fails while a deleted account shares its username or email with another account.
+
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+
+DROP INDEX IF EXISTS idx_users_username;
+CREATE UNIQUE INDEX idx_users_username ON users (username);
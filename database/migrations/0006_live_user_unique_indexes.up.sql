@@ -0,0 +1,13 @@
+-- Usernames and emails only need to be unique among accounts that have not been soft-deleted, so a
+-- deleted account no longer blocks registering again with the same address. The unique constraints
+-- AutoMigrate may have created, under gorm's current or older default names, are dropped as well.
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_username;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+DROP INDEX IF EXISTS idx_users_username;
+CREATE UNIQUE INDEX idx_users_username ON users (username) WHERE deleted_at IS NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_email;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users (email) WHERE deleted_at IS NULL;
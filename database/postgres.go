@@ -1,14 +1,13 @@
 package database
 
 import (
-	"errors"
 	"fmt"
-	"os"
 
-	"finalproject/core"
+	"finalproject/config"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 )
 
 type Postgres struct {
@@ -16,31 +15,27 @@ type Postgres struct {
 	Err error
 }
 
-func NewPostgres() (*Postgres, error) {
-	// Read environment variables for database credentials
-	host := os.Getenv("PGHOST")
-	user := os.Getenv("PGUSER")
-	// password := os.Getenv("PGPASS")
-	dbname := os.Getenv("PGDBNAME")
-	port := os.Getenv("PGPORT")
-
-	// Validate required environment variables
-	if host == "" || user == "" || dbname == "" || port == "" {
-		return nil, errors.New("missing required database environment variables")
-	}
-
-	// Construct the data source name (DSN)
-	dsn := fmt.Sprintf("host=%s user=%s password='' dbname=%s port=%s sslmode=disable",
-		host, user, dbname, port)
-
+// NewPostgres connects to the database; queries are logged through logger.
+func NewPostgres(cfg config.DatabaseConfig, logger gormlogger.Interface) (*Postgres, error) {
 	// Open a connection to the PostgreSQL database
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{}) // Use gorm.Config for customization (optional)
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{
+		TranslateError: true, // Translate driver errors such as unique violations into gorm errors
+		Logger:         logger,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Perform database migrations (optional, based on your needs)
-	db.AutoMigrate(&core.User{}, &core.SocialMedia{}, &core.Photo{}, &core.Comment{})
+	// Size the connection pool
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure connection pool: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	// The schema is not touched here; it is managed by the embedded migrations, see Migrator
 
 	// Return the Postgres struct with connection and error
 	return &Postgres{DB: db, Err: err}, nil
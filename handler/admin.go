@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"finalproject/core"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	users      *service.UserService
+	loginGuard *service.LoginGuard
+}
+
+func NewAdminHandler(users *service.UserService, loginGuard *service.LoginGuard) *AdminHandler {
+	return &AdminHandler{users: users, loginGuard: loginGuard}
+}
+
+type RoleUpdate struct {
+	Role core.Role `json:"role"`
+}
+
+func (h *AdminHandler) GetUsers(c *gin.Context) {
+	// 1. Retrieve all users from database
+	users, err := h.users.FindAll(c.Request.Context())
+	if err != nil {
+		respondInternalError(c, err, "users_failed")
+		return
+	}
+
+	// 2. Send successful response with users data
+	c.JSON(http.StatusOK, newPrivateProfiles(users))
+}
+
+func (h *AdminHandler) SetRole(c *gin.Context) {
+	// 1. Get user ID from URL parameter
+	userID, ok := parseID(c, "user")
+	if !ok {
+		return
+	}
+
+	// 2. Parse request body
+	var request RoleUpdate
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 3. Change the role of the user
+	user, err := h.users.SetRole(c.Request.Context(), userID, request.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRole):
+			respondError(c, http.StatusBadRequest, "invalid_role")
+		case errors.Is(err, service.ErrNotFound):
+			respondError(c, http.StatusNotFound, "user_not_found")
+		case errors.Is(err, service.ErrLastAdmin):
+			respondError(c, http.StatusConflict, "last_admin_demote")
+		default:
+			respondInternalError(c, err, "role_update_failed")
+		}
+		return
+	}
+
+	// 4. Send successful response with the updated user
+	c.JSON(http.StatusOK, newPrivateProfile(user))
+}
+
+type UnlockRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
+
+func (h *AdminHandler) Unlock(c *gin.Context) {
+	// 1. Parse request body
+	var request UnlockRequest
+	if err := c.ShouldBindJSON(&request); err != nil || (request.Email == "" && request.IP == "") {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. Clear the failure counters of the account and/or client IP
+	if request.Email != "" {
+		if err := h.loginGuard.Unlock(c.Request.Context(), request.Email); err != nil {
+			respondInternalError(c, err, "unlock_account_failed")
+			return
+		}
+	}
+	if request.IP != "" {
+		if err := h.loginGuard.UnlockIP(c.Request.Context(), request.IP); err != nil {
+			respondInternalError(c, err, "unlock_ip_failed")
+			return
+		}
+	}
+
+	// 3. Send successful unlock response
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "unlocked")})
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"finalproject/middleware"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	auth *service.AuthService
+}
+
+func NewAuthHandler(auth *service.AuthService) *AuthHandler {
+	return &AuthHandler{auth: auth}
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// clientInfo captures the device details stored alongside a session.
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	// 1. Parse request body
+	var request RefreshTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.RefreshToken == "" {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. Rotate the refresh token
+	tokens, err := h.auth.Refresh(c.Request.Context(), request.RefreshToken, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			respondError(c, http.StatusUnauthorized, "refresh_token_reused")
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			respondError(c, http.StatusUnauthorized, "invalid_refresh_token")
+		default:
+			respondInternalError(c, err, "refresh_failed")
+		}
+		return
+	}
+
+	// 3. Send the new token pair
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	// 1. Parse request body
+	var request RefreshTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.RefreshToken == "" {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. Revoke the session the token belongs to
+	if err := h.auth.Logout(c.Request.Context(), request.RefreshToken); err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			respondError(c, http.StatusUnauthorized, "invalid_refresh_token")
+		} else {
+			respondInternalError(c, err, "logout_failed")
+		}
+		return
+	}
+
+	// 3. Send successful logout response
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "logged_out")})
+}
+
+func (h *AuthHandler) Sessions(c *gin.Context) {
+	sessions, err := h.auth.Sessions(c.Request.Context(), middleware.CurrentUser(c).ID)
+	if err != nil {
+		respondInternalError(c, err, "sessions_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	// 1. Get session ID from URL parameter
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		respondError(c, http.StatusBadRequest, "missing_session_id")
+		return
+	}
+
+	// 2. Revoke the session if it belongs to the caller
+	err := h.auth.RevokeSession(c.Request.Context(), middleware.CurrentUser(c).ID, sessionID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "session_not_found")
+		} else {
+			respondInternalError(c, err, "session_revoke_failed")
+		}
+		return
+	}
+
+	// 3. Send successful revoke response
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "session_revoked")})
+}
+
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.auth.JWKS())
+}
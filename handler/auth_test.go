@@ -0,0 +1,32 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReusedRefreshTokenRevokesFamily(t *testing.T) {
+	router, _ := newTestRouter(t)
+	register(t, router, "jane@example.com", "secret1")
+	first := login(t, router, "jane@example.com", "secret1")
+
+	code, second := request(t, router, http.MethodPost, "/auth/refresh", "", gin.H{"refreshToken": first["refreshToken"]})
+	if code != http.StatusOK {
+		t.Fatalf("refresh: %d %v", code, second)
+	}
+
+	// Presenting the rotated token again is treated as theft
+	code, body := request(t, router, http.MethodPost, "/auth/refresh", "", gin.H{"refreshToken": first["refreshToken"]})
+	if code != http.StatusUnauthorized || body["code"] != "refresh_token_reused" {
+		t.Fatalf("reused refresh token: %d %v", code, body)
+	}
+
+	// ...which revokes the token issued by the legitimate rotation as well
+	code, body = request(t, router, http.MethodPost, "/auth/refresh", "", gin.H{"refreshToken": second["refreshToken"]})
+	if code != http.StatusUnauthorized {
+		t.Errorf("refresh token of the revoked family: %d %v", code, body)
+	}
+
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"finalproject/core"
+	"finalproject/middleware"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler struct {
+	comments *service.CommentService
+}
+
+func NewCommentHandler(comments *service.CommentService) *CommentHandler {
+	return &CommentHandler{comments: comments}
+}
+
+func (h *CommentHandler) GetAll(c *gin.Context) {
+	// 1. Find all comments
+	comments, err := h.comments.FindAll(c.Request.Context())
+	if err != nil {
+		respondInternalError(c, err, "comments_failed")
+		return
+	}
+
+	// 2. Send successful response with all comments
+	c.JSON(http.StatusOK, newCommentResponses(comments))
+}
+
+func (h *CommentHandler) GetOne(c *gin.Context) {
+	// 1. Get comment ID from URL parameter
+	commentID, ok := parseID(c, "comment")
+	if !ok {
+		return
+	}
+
+	// 2. Find comment by ID
+	comment, err := h.comments.FindByPublicID(c.Request.Context(), commentID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "comment_not_found")
+		} else {
+			respondInternalError(c, err, "comment_find_failed")
+		}
+		return
+	}
+
+	// 3. Send successful response with the comment
+	c.JSON(http.StatusOK, newCommentResponse(comment))
+}
+
+// CommentCreate lists the fields a client may set when commenting on a photo.
+type CommentCreate struct {
+	Message string `json:"message"`
+	PhotoID string `json:"photoId"`
+}
+
+func (h *CommentHandler) Create(c *gin.Context) {
+	// 1. Parse request body
+	var request CommentCreate
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. Validate comment data
+	newComment := core.Comment{
+		Message: request.Message,
+		UserID:  middleware.CurrentUser(c).ID, // Comments always belong to the caller
+	}
+	if err := newComment.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// 3. Save comment in database; the photo must exist
+	if err := h.comments.Create(c.Request.Context(), &newComment, request.PhotoID); err != nil {
+		if errors.Is(err, service.ErrPhotoNotFound) {
+			respondError(c, http.StatusUnprocessableEntity, "photo_not_found")
+		} else {
+			respondInternalError(c, err, "comment_create_failed")
+		}
+		return
+	}
+	currentUser := middleware.CurrentUser(c)
+	newComment.User = &currentUser
+
+	// 4. Send successful creation response
+	c.JSON(http.StatusCreated, newCommentResponse(newComment))
+}
+
+// CommentUpdate replaces the editable fields of a comment.
+type CommentUpdate struct {
+	Message string `json:"message"`
+}
+
+func (h *CommentHandler) Update(c *gin.Context) {
+	// 1. Get comment ID from URL parameter
+	commentID, ok := parseID(c, "comment")
+	if !ok {
+		return
+	}
+
+	// 2. Parse request body
+	var updatedCommentData CommentUpdate
+	if err := c.ShouldBindJSON(&updatedCommentData); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 3. Find comment by ID
+	comment, err := h.comments.FindByPublicID(c.Request.Context(), commentID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "comment_not_found")
+		} else {
+			respondInternalError(c, err, "comment_find_failed")
+		}
+		return
+	}
+
+	// 4. Apply the editable fields and validate the result
+	comment.Message = updatedCommentData.Message
+	if err := comment.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// 5. Save updated comment in database
+	if err := h.comments.Update(c.Request.Context(), &comment); err != nil {
+		respondInternalError(c, err, "comment_update_failed")
+		return
+	}
+
+	// 6. Send successful update response
+	c.JSON(http.StatusOK, newCommentResponse(comment))
+}
+
+func (h *CommentHandler) Delete(c *gin.Context) {
+	// 1. Get comment ID from URL parameter
+	commentID, ok := parseID(c, "comment")
+	if !ok {
+		return
+	}
+
+	// 2. Find comment by ID
+	comment, err := h.comments.FindByPublicID(c.Request.Context(), commentID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "comment_not_found")
+		} else {
+			respondInternalError(c, err, "comment_find_failed")
+		}
+		return
+	}
+
+	// 3. Delete comment from database
+	if err := h.comments.Delete(c.Request.Context(), &comment); err != nil {
+		respondInternalError(c, err, "comment_delete_failed")
+		return
+	}
+
+	// 4. Send successful delete response
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "comment_deleted")})
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"finalproject/middleware"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerificationHandler struct {
+	emailVerification *service.EmailVerificationService
+	loginGuard        *service.LoginGuard
+}
+
+func NewEmailVerificationHandler(emailVerification *service.EmailVerificationService, loginGuard *service.LoginGuard) *EmailVerificationHandler {
+	return &EmailVerificationHandler{emailVerification: emailVerification, loginGuard: loginGuard}
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+func (h *EmailVerificationHandler) Verify(c *gin.Context) {
+	// 1. Parse request body
+	var request VerifyEmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Token == "" {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. Confirm the address the link was sent to
+	user, err := h.emailVerification.Verify(c.Request.Context(), request.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidVerificationToken):
+			respondError(c, http.StatusBadRequest, "invalid_verification_link")
+		case errors.Is(err, service.ErrEmailTaken):
+			respondError(c, http.StatusConflict, "email_taken")
+		default:
+			respondInternalError(c, err, "email_verify_failed")
+		}
+		return
+	}
+
+	// 3. Send successful verification response
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "email_verified"), "email": user.Email})
+}
+
+func (h *EmailVerificationHandler) Resend(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+
+	// 1. Limit the emails per address and per client IP, so the endpoint cannot flood an inbox
+	email := user.PendingEmail
+	if email == "" {
+		email = user.Email
+	}
+	if err := h.loginGuard.AllowVerificationEmail(c.Request.Context(), email, c.ClientIP()); err != nil {
+		respondGuardError(c, err)
+		return
+	}
+
+	// 2. Send a new link to the address awaiting confirmation
+	err := h.emailVerification.SendVerification(c.Request.Context(), user)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailAlreadyVerified) {
+			respondError(c, http.StatusConflict, "email_already_verified")
+		} else {
+			respondInternalError(c, err, "verification_email_failed")
+		}
+		return
+	}
+
+	// 3. Send accepted response
+	c.JSON(http.StatusAccepted, gin.H{"message": localize(c, "verification_email_sent")})
+}
@@ -0,0 +1,27 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"finalproject/handler"
+	"finalproject/service"
+)
+
+func TestResendVerificationIsThrottled(t *testing.T) {
+	router, _ := newTestRouter(t, func(deps *service.Dependencies, _ *handler.RouterConfig) {
+		deps.Lockout = service.LockoutPolicy{ResetsPerAddress: 2, ResetsPerIP: 10}
+	})
+	register(t, router, "jane@example.com", "secret1")
+	token := login(t, router, "jane@example.com", "secret1")["token"].(string)
+
+	for i := 0; i < 2; i++ {
+		if code, body := request(t, router, http.MethodPost, "/auth/email/resend", token, nil); code != http.StatusAccepted {
+			t.Fatalf("resend %d: %d %v", i+1, code, body)
+		}
+	}
+	code, body := request(t, router, http.MethodPost, "/auth/email/resend", token, nil)
+	if code != http.StatusTooManyRequests || body["code"] != "email_resend_throttled" {
+		t.Errorf("over the limit: %d %v", code, body)
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"finalproject/core"
+	"finalproject/i18n"
+	"finalproject/middleware"
+	"finalproject/problem"
+	"finalproject/service"
+	"finalproject/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers report failures with the respond* helpers below, which record a problem.Error on the
+// context; middleware.Errors renders it as application/problem+json once the handler returns.
+
+// localize renders the message with the given catalog code in the locale chosen for the request.
+func localize(c *gin.Context, code string, args ...any) string {
+	return i18n.T(middleware.Locale(c), code, args...)
+}
+
+// respondError reports a client error with the given status and message code.
+func respondError(c *gin.Context, status int, code string) {
+	c.Error(problem.New(status, code))
+}
+
+// respondInternalError reports a 500 with the message code; err is logged, never sent.
+func respondInternalError(c *gin.Context, err error, code string) {
+	c.Error(problem.Internal(err, code))
+}
+
+// parseID reads the public ID in the :id URL parameter, answering 400 when it is missing or malformed.
+// resource is the key used in message codes, e.g. "photo" for "invalid_photo_id".
+func parseID(c *gin.Context, resource string) (string, bool) {
+	id := c.Param("id")
+	if id == "" {
+		respondError(c, http.StatusBadRequest, "missing_"+resource+"_id")
+		return "", false
+	}
+	if !core.ValidPublicID(id) {
+		respondError(c, http.StatusBadRequest, "invalid_"+resource+"_id")
+		return "", false
+	}
+
+	return id, true
+}
+
+// respondValidationError answers 400 listing every invalid field when err is validation.Errors.
+func respondValidationError(c *gin.Context, err error) {
+	var fieldErrors validation.Errors
+	if errors.As(err, &fieldErrors) {
+		c.Error(fieldErrors)
+		return
+	}
+	respondError(c, http.StatusBadRequest, "invalid_request_body")
+}
+
+// respondGuardError answers 429 with Retry-After for throttled logins, 500 for anything else.
+func respondGuardError(c *gin.Context, err error) {
+	var throttled *service.ThrottledError
+	if errors.As(err, &throttled) {
+		c.Error(throttled)
+		return
+	}
+	respondInternalError(c, err, "login_check_failed")
+}
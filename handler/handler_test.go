@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"finalproject/handler"
+	"finalproject/helpers"
+	"finalproject/mailer"
+	"finalproject/repository"
+	"finalproject/repository/memory"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter serves the whole API on memory repositories, which are returned for setup and inspection.
+// Options adjust the dependencies and router configuration before the router is built.
+func newTestRouter(t *testing.T, options ...func(*service.Dependencies, *handler.RouterConfig)) (http.Handler, repository.Repositories) {
+	t.Helper()
+	repos := memory.NewRepositories()
+	return newTestRouterOn(t, repos, options...), repos
+}
+
+// newTestRouterOn serves the whole API on the given repositories.
+func newTestRouterOn(t *testing.T, repos repository.Repositories, options ...func(*service.Dependencies, *handler.RouterConfig)) http.Handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	keys, err := helpers.NewKeyRing(helpers.AlgorithmEdDSA, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	passwords, err := helpers.NewPasswords("argon2id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deps := service.Dependencies{
+		Keys:      keys,
+		Passwords: passwords,
+		Mailer:    mailer.NewFileMailer(t.TempDir(), "mygram <no-reply@mygram.test>"),
+		AppURL:    "http://mygram.test",
+	}
+	cfg := handler.RouterConfig{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	for _, option := range options {
+		option(&deps, &cfg)
+	}
+
+	services := service.New(repos, deps)
+	t.Cleanup(services.PasswordReset.Wait)
+	return handler.NewRouter(services, cfg)
+}
+
+// request sends body as JSON and decodes the JSON response into a map.
+func request(t *testing.T, router http.Handler, method, path, token string, body any) (int, map[string]any) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]any
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w.Code, response
+}
+
+func register(t *testing.T, router http.Handler, email, password string) {
+	t.Helper()
+	username := strings.Split(email, "@")[0]
+	if code, body := request(t, router, http.MethodPost, "/register", "", gin.H{
+		"email": email, "password": password, "username": username, "age": 20,
+	}); code != http.StatusCreated {
+		t.Fatalf("register: %d %v", code, body)
+	}
+}
+
+func login(t *testing.T, router http.Handler, email, password string) map[string]any {
+	t.Helper()
+	code, body := request(t, router, http.MethodPost, "/login", "", gin.H{"email": email, "password": password})
+	if code != http.StatusOK {
+		t.Fatalf("login: %d %v", code, body)
+	}
+	return body
+}
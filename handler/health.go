@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds all readiness checks together, so a hung dependency fails the probe instead of stalling it.
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck reports whether one dependency can serve traffic.
+type ReadinessCheck func(ctx context.Context) error
+
+type HealthHandler struct {
+	checks   map[string]ReadinessCheck
+	draining atomic.Bool
+}
+
+func NewHealthHandler(checks map[string]ReadinessCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+// Drain makes readiness fail from now on, so load balancers stop routing here while requests finish.
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Live answers as long as the process can serve HTTP at all.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready answers 503 while draining or when any check fails. Check errors are logged, not sent,
+// since the endpoint is unauthenticated.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	// 1. Refuse traffic once shutdown has started
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+
+	// 2. Run every check, reporting each one
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	status, results := http.StatusOK, make(map[string]string, len(h.checks))
+	for name, check := range h.checks {
+		if err := check(ctx); err != nil {
+			slog.WarnContext(ctx, "readiness check failed", "check", name, "error", err)
+			status, results[name] = http.StatusServiceUnavailable, "unavailable"
+			continue
+		}
+		results[name] = "ok"
+	}
+
+	// 3. Send the results
+	overall := "ok"
+	if status != http.StatusOK {
+		overall = "unavailable"
+	}
+	c.JSON(status, gin.H{"status": overall, "checks": results})
+}
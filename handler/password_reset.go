@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"finalproject/core"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetHandler struct {
+	passwordReset *service.PasswordResetService
+	loginGuard    *service.LoginGuard
+}
+
+func NewPasswordResetHandler(passwordReset *service.PasswordResetService, loginGuard *service.LoginGuard) *PasswordResetHandler {
+	return &PasswordResetHandler{passwordReset: passwordReset, loginGuard: loginGuard}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (h *PasswordResetHandler) Forgot(c *gin.Context) {
+	// 1. Parse request body
+	var request ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Email == "" {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. Limit the requests per address and per client IP, so the endpoint cannot flood an inbox
+	if err := h.loginGuard.AllowReset(c.Request.Context(), request.Email, c.ClientIP()); err != nil {
+		respondGuardError(c, err)
+		return
+	}
+
+	// 3. Email a reset link in the background if the account exists
+	h.passwordReset.Forgot(c.Request.Context(), request.Email)
+
+	// 4. Answer the same way whether or not the email is registered
+	c.JSON(http.StatusAccepted, gin.H{"message": localize(c, "reset_link_sent")})
+}
+
+func (h *PasswordResetHandler) Reset(c *gin.Context) {
+	// 1. Parse request body
+	var request ResetPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Token == "" {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. Validate the new password
+	if err := core.ValidatePassword(request.Password); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// 3. Redeem the token and set the new password
+	if err := h.passwordReset.Reset(c.Request.Context(), request.Token, request.Password); err != nil {
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			respondError(c, http.StatusBadRequest, "invalid_reset_token")
+		} else {
+			respondInternalError(c, err, "password_reset_failed")
+		}
+		return
+	}
+
+	// 4. Send successful reset response
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "password_reset")})
+}
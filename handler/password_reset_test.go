@@ -0,0 +1,37 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"finalproject/handler"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestForgotPasswordIsThrottledPerAddressAndIP(t *testing.T) {
+	router, _ := newTestRouter(t, func(deps *service.Dependencies, _ *handler.RouterConfig) {
+		deps.Lockout = service.LockoutPolicy{ResetsPerAddress: 2, ResetsPerIP: 3}
+	})
+	register(t, router, "jane@example.com", "secret1")
+
+	forgot := func(email string) (int, map[string]any) {
+		return request(t, router, http.MethodPost, "/auth/password/forgot", "", gin.H{"email": email})
+	}
+	for i := 0; i < 2; i++ {
+		if code, body := forgot("jane@example.com"); code != http.StatusAccepted {
+			t.Fatalf("request %d: %d %v", i+1, code, body)
+		}
+	}
+
+	// The third request for the address is refused, and still counts against the client IP
+	code, body := forgot("jane@example.com")
+	if code != http.StatusTooManyRequests || body["code"] != "password_reset_throttled" {
+		t.Fatalf("over the address limit: %d %v", code, body)
+	}
+	code, body = forgot("nobody@example.com")
+	if code != http.StatusTooManyRequests || body["code"] != "password_reset_throttled" {
+		t.Errorf("over the IP limit: %d %v", code, body)
+	}
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"finalproject/core"
+	"finalproject/middleware"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PhotoHandler struct {
+	photos *service.PhotoService
+}
+
+func NewPhotoHandler(photos *service.PhotoService) *PhotoHandler {
+	return &PhotoHandler{photos: photos}
+}
+
+func (h *PhotoHandler) GetAll(c *gin.Context) {
+	// Find all photos
+	photos, err := h.photos.FindAll(c.Request.Context())
+	if err != nil {
+		respondInternalError(c, err, "photos_failed")
+		return
+	}
+
+	// Respond with the list of photos
+	c.JSON(http.StatusOK, newPhotoResponses(photos))
+}
+
+func (h *PhotoHandler) GetOne(c *gin.Context) {
+	// 1. Get photo ID from URL parameter
+	photoID, ok := parseID(c, "photo")
+	if !ok {
+		return
+	}
+
+	// 2. Find photo by ID
+	photo, err := h.photos.FindByPublicID(c.Request.Context(), photoID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "photo_not_found")
+		} else {
+			respondInternalError(c, err, "photo_find_failed")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newPhotoResponse(photo))
+}
+
+// PhotoCreate lists the fields a client may set when posting a photo.
+type PhotoCreate struct {
+	Title    string `json:"title"`
+	Caption  string `json:"caption"`
+	PhotoURL string `json:"photoUrl"`
+}
+
+func (h *PhotoHandler) Create(c *gin.Context) {
+	// 1. Parse request body
+	var request PhotoCreate
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+	newPhoto := core.Photo{Title: request.Title, Caption: request.Caption, PhotoURL: request.PhotoURL}
+
+	// 2. Validate photo data
+	if err := newPhoto.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// 3. Save photo information in database; photos always belong to the caller
+	if err := h.photos.Create(c.Request.Context(), middleware.CurrentUser(c), &newPhoto); err != nil {
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			respondError(c, http.StatusForbidden, "email_not_verified")
+		} else {
+			respondInternalError(c, err, "photo_create_failed")
+		}
+		return
+	}
+
+	// 4. Send successful creation response
+	c.JSON(http.StatusCreated, newPhotoResponse(newPhoto))
+}
+
+// PhotoUpdate replaces the editable fields of a photo.
+type PhotoUpdate struct {
+	Title    string `json:"title"`
+	Caption  string `json:"caption"`
+	PhotoURL string `json:"photoUrl"`
+}
+
+func (h *PhotoHandler) Update(c *gin.Context) {
+	// 1. Get photo ID from URL parameter
+	photoID, ok := parseID(c, "photo")
+	if !ok {
+		return
+	}
+
+	// 2. Parse request body
+	var updatedPhotoData PhotoUpdate
+	if err := c.ShouldBindJSON(&updatedPhotoData); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 3. Find photo by ID
+	photo, err := h.photos.FindByPublicID(c.Request.Context(), photoID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "photo_not_found")
+		} else {
+			respondInternalError(c, err, "photo_find_failed")
+		}
+		return
+	}
+
+	// 4. Apply the editable fields and validate the result
+	photo.Title = updatedPhotoData.Title
+	photo.Caption = updatedPhotoData.Caption
+	photo.PhotoURL = updatedPhotoData.PhotoURL
+	if err := photo.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// 5. Save updated photo in database
+	if err := h.photos.Update(c.Request.Context(), &photo); err != nil {
+		respondInternalError(c, err, "photo_update_failed")
+		return
+	}
+
+	// 6. Send successful update response
+	c.JSON(http.StatusOK, newPhotoResponse(photo))
+}
+
+func (h *PhotoHandler) Delete(c *gin.Context) {
+	// 1. Get photo ID from URL parameter
+	photoID, ok := parseID(c, "photo")
+	if !ok {
+		return
+	}
+
+	// 2. Find photo by ID
+	photo, err := h.photos.FindByPublicID(c.Request.Context(), photoID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "photo_not_found")
+		} else {
+			respondInternalError(c, err, "photo_find_failed")
+		}
+		return
+	}
+
+	// 3. Delete photo from database, together with its comments unless the policy restricts it
+	if err := h.photos.Delete(c.Request.Context(), &photo); err != nil {
+		if errors.Is(err, service.ErrHasDependents) {
+			respondError(c, http.StatusConflict, "photo_has_comments")
+		} else {
+			respondInternalError(c, err, "photo_delete_failed")
+		}
+		return
+	}
+
+	// 4. Send successful delete response
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "photo_deleted")})
+}
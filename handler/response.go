@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"time"
+
+	"finalproject/core"
+)
+
+// Response DTOs decouple the JSON API from the core models so that new model fields
+// (password hashes, TOTP secrets, gorm bookkeeping) are never serialized by accident.
+// Every ID in a response is a public ID; internal keys stay in the database.
+
+// PublicProfile is what any authenticated user may see about another user.
+type PublicProfile struct {
+	ID              string    `json:"id"`
+	Username        string    `json:"username"`
+	ProfileImageURL string    `json:"profileImageUrl"`
+	Role            core.Role `json:"role"`
+}
+
+func newPublicProfile(user core.User) PublicProfile {
+	return PublicProfile{
+		ID:              user.PublicID,
+		Username:        user.Username,
+		ProfileImageURL: user.ProfileImageURL,
+		Role:            user.Role,
+	}
+}
+
+// PrivateProfile is what users see about themselves, and admins about any user.
+type PrivateProfile struct {
+	ID               string    `json:"id"`
+	Username         string    `json:"username"`
+	Email            string    `json:"email"`
+	PendingEmail     string    `json:"pendingEmail,omitempty"`
+	EmailVerified    bool      `json:"emailVerified"`
+	Age              int       `json:"age"`
+	ProfileImageURL  string    `json:"profileImageUrl"`
+	Role             core.Role `json:"role"`
+	Locale           string    `json:"locale"`
+	TwoFactorEnabled bool      `json:"twoFactorEnabled"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+func newPrivateProfile(user core.User) PrivateProfile {
+	return PrivateProfile{
+		ID:               user.PublicID,
+		Username:         user.Username,
+		Email:            user.Email,
+		PendingEmail:     user.PendingEmail,
+		EmailVerified:    user.EmailVerified(),
+		Age:              user.Age,
+		ProfileImageURL:  user.ProfileImageURL,
+		Role:             user.Role,
+		Locale:           user.Locale,
+		TwoFactorEnabled: user.TwoFactorEnabled(),
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+}
+
+func newPrivateProfiles(users []core.User) []PrivateProfile {
+	profiles := make([]PrivateProfile, 0, len(users))
+	for _, user := range users {
+		profiles = append(profiles, newPrivateProfile(user))
+	}
+	return profiles
+}
+
+// PhotoResponse is a photo with a summary of its author.
+// User is null when the author could not be loaded, e.g. after the account was deleted.
+type PhotoResponse struct {
+	ID        string         `json:"id"`
+	Title     string         `json:"title"`
+	Caption   string         `json:"caption"`
+	PhotoURL  string         `json:"photoUrl"`
+	UserID    string         `json:"userId"`
+	User      *PublicProfile `json:"user"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+func newPhotoResponse(photo core.Photo) PhotoResponse {
+	return PhotoResponse{
+		ID:        photo.PublicID,
+		Title:     photo.Title,
+		Caption:   photo.Caption,
+		PhotoURL:  photo.PhotoURL,
+		UserID:    ownerID(photo.User),
+		User:      newAuthor(photo.User),
+		CreatedAt: photo.CreatedAt,
+		UpdatedAt: photo.UpdatedAt,
+	}
+}
+
+func newPhotoResponses(photos []core.Photo) []PhotoResponse {
+	responses := make([]PhotoResponse, 0, len(photos))
+	for _, photo := range photos {
+		responses = append(responses, newPhotoResponse(photo))
+	}
+	return responses
+}
+
+// newAuthor returns the public profile of a loaded association, or nil when it was not loaded.
+func newAuthor(user *core.User) *PublicProfile {
+	if user == nil {
+		return nil
+	}
+	profile := newPublicProfile(*user)
+	return &profile
+}
+
+// ownerID returns the public ID of a loaded user association, or "" when it was not loaded.
+func ownerID(user *core.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.PublicID
+}
+
+// PhotoSummary identifies the photo a comment belongs to.
+type PhotoSummary struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	PhotoURL string `json:"photoUrl"`
+}
+
+// CommentResponse is a comment with summaries of its author and photo.
+type CommentResponse struct {
+	ID        string         `json:"id"`
+	Message   string         `json:"message"`
+	PhotoID   string         `json:"photoId"`
+	Photo     *PhotoSummary  `json:"photo"`
+	UserID    string         `json:"userId"`
+	User      *PublicProfile `json:"user"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+func newCommentResponse(comment core.Comment) CommentResponse {
+	var photo *PhotoSummary
+	if comment.Photo != nil {
+		photo = &PhotoSummary{
+			ID:       comment.Photo.PublicID,
+			Title:    comment.Photo.Title,
+			PhotoURL: comment.Photo.PhotoURL,
+		}
+	}
+	response := CommentResponse{
+		ID:        comment.PublicID,
+		Message:   comment.Message,
+		Photo:     photo,
+		UserID:    ownerID(comment.User),
+		User:      newAuthor(comment.User),
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+	if photo != nil {
+		response.PhotoID = photo.ID
+	}
+	return response
+}
+
+func newCommentResponses(comments []core.Comment) []CommentResponse {
+	responses := make([]CommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		responses = append(responses, newCommentResponse(comment))
+	}
+	return responses
+}
+
+// SocialMediaResponse is a social media entry with a summary of its owner.
+type SocialMediaResponse struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	SocialMediaURL string         `json:"socialMediaUrl"`
+	UserID         string         `json:"userId"`
+	User           *PublicProfile `json:"user"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+func newSocialMediaResponse(socialMedia core.SocialMedia) SocialMediaResponse {
+	return SocialMediaResponse{
+		ID:             socialMedia.PublicID,
+		Name:           socialMedia.Name,
+		SocialMediaURL: socialMedia.SocialMediaURL,
+		UserID:         ownerID(socialMedia.User),
+		User:           newAuthor(socialMedia.User),
+		CreatedAt:      socialMedia.CreatedAt,
+		UpdatedAt:      socialMedia.UpdatedAt,
+	}
+}
+
+func newSocialMediaResponses(socialMedia []core.SocialMedia) []SocialMediaResponse {
+	responses := make([]SocialMediaResponse, 0, len(socialMedia))
+	for _, entry := range socialMedia {
+		responses = append(responses, newSocialMediaResponse(entry))
+	}
+	return responses
+}
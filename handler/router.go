@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"finalproject/core"
+	"finalproject/metrics"
+	"finalproject/middleware"
+	"finalproject/service"
+	"finalproject/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouterConfig holds the operational parts of the router.
+type RouterConfig struct {
+	// Health answers /healthz and /readyz; nil reports ready without checks.
+	Health *HealthHandler
+	// Logger receives the access log; nil uses slog.Default.
+	Logger *slog.Logger
+	// LogSampleRate is the share of successful requests written to the access log.
+	LogSampleRate float64
+	// TrustedProxies are the IPs or CIDRs whose X-Forwarded-For header decides the client IP used for
+	// throttling and sessions; nil trusts none, so a client cannot pick its own IP.
+	TrustedProxies []string
+	// Metrics instruments every request; nil disables it. /metrics is served by the admin listener,
+	// see MetricsHandler, so the API port does not expose it.
+	Metrics *metrics.Metrics
+}
+
+// NewRouter registers every endpoint on a gin engine backed by the given services.
+// It does not touch the database directly, so tests can pass services built on memory repositories.
+func NewRouter(services service.Services, cfg RouterConfig) *gin.Engine {
+	health := cfg.Health
+	if health == nil {
+		health = NewHealthHandler(nil)
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	userHandler := NewUserHandler(services.Users, services.Auth, services.EmailVerification, services.TwoFactor, services.LoginGuard)
+	authHandler := NewAuthHandler(services.Auth)
+	passwordResetHandler := NewPasswordResetHandler(services.PasswordReset, services.LoginGuard)
+	emailVerificationHandler := NewEmailVerificationHandler(services.EmailVerification, services.LoginGuard)
+	twoFactorHandler := NewTwoFactorHandler(services.TwoFactor, services.LoginGuard)
+	adminHandler := NewAdminHandler(services.Users, services.LoginGuard)
+	photoHandler := NewPhotoHandler(services.Photos)
+	commentHandler := NewCommentHandler(services.Comments)
+	socialMediaHandler := NewSocialMediaHandler(services.SocialMedia)
+
+	authentication := middleware.Authentication(services.Auth, services.Users)
+
+	// Errors sits outside Recovery so that panics are rendered as problems too
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Error("invalid trusted proxies, trusting none", "error", err)
+		router.SetTrustedProxies(nil)
+	}
+	// RequestID comes first so every later record carries the ID, then the server span so the access log carries the trace;
+	// probes and scrapes are not logged, they would drown out real traffic
+	router.Use(middleware.RequestID(), tracing.Middleware())
+	if cfg.Metrics != nil {
+		router.Use(cfg.Metrics.Middleware())
+	}
+	router.Use(middleware.AccessLog(logger, cfg.LogSampleRate, "/healthz", "/readyz"), middleware.Localization(), middleware.Errors(), middleware.Recovery())
+	router.NoRoute(func(c *gin.Context) {
+		respondError(c, http.StatusNotFound, "not_found")
+	})
+
+	// Liveness and readiness probes
+	router.GET("/healthz", health.Live)
+	router.GET("/readyz", health.Ready)
+
+	// User endpoints
+	router.POST("/register", userHandler.Register)
+	router.POST("/login", userHandler.Login)
+
+	// Public signing keys for services verifying mygram tokens
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+
+	// Session endpoints
+	router.POST("/auth/refresh", authHandler.Refresh)
+	router.POST("/auth/logout", authHandler.Logout)
+	router.POST("/auth/password/forgot", passwordResetHandler.Forgot)
+	router.POST("/auth/password/reset", passwordResetHandler.Reset)
+	router.POST("/auth/email/verify", emailVerificationHandler.Verify)
+	router.POST("/auth/email/resend", authentication, emailVerificationHandler.Resend)
+
+	// Two-factor endpoints
+	router.POST("/auth/2fa/verify", twoFactorHandler.Verify)
+
+	twoFactor := router.Group("/auth/2fa", authentication)
+	twoFactor.POST("/enroll", twoFactorHandler.Enroll)
+	twoFactor.POST("/confirm", twoFactorHandler.Confirm)
+	twoFactor.POST("/disable", twoFactorHandler.Disable)
+	twoFactor.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+
+	sessions := router.Group("/auth/sessions", authentication)
+	sessions.GET("", authHandler.Sessions)
+	sessions.DELETE("/:id", authHandler.RevokeSession)
+
+	users := router.Group("/users", authentication)
+	users.GET("/me", userHandler.Me)
+	users.GET("/:id", userHandler.GetOne)
+	userAuthorization := middleware.UserAuthorization(core.PermissionManageUsers)
+	users.PUT("/:id", userAuthorization, userHandler.Update)
+	users.DELETE("/:id", userAuthorization, userHandler.Delete)
+
+	// Photo endpoints
+	photos := router.Group("/photos", authentication)
+	photos.GET("", photoHandler.GetAll)
+	photos.GET("/:id", photoHandler.GetOne)
+	photos.POST("", photoHandler.Create)
+	photos.PUT("/:id", middleware.PhotoAuthorization(services.Photos), photoHandler.Update)
+	photos.DELETE("/:id", middleware.PhotoAuthorization(services.Photos, core.PermissionDeleteAnyPhoto), photoHandler.Delete)
+
+	// Comment endpoints
+	comments := router.Group("/comments", authentication)
+	comments.GET("", commentHandler.GetAll)
+	comments.GET("/:id", commentHandler.GetOne)
+	comments.POST("", commentHandler.Create)
+	comments.PUT("/:id", middleware.CommentAuthorization(services.Comments), commentHandler.Update)
+	comments.DELETE("/:id", middleware.CommentAuthorization(services.Comments, core.PermissionDeleteAnyComment), commentHandler.Delete)
+
+	// Social Media endpoints
+	socialMediaAuthorization := middleware.SocialMediaAuthorization(services.SocialMedia)
+	socialMedia := router.Group("/social-media", authentication)
+	socialMedia.GET("", socialMediaHandler.GetAll)
+	socialMedia.GET("/:id", socialMediaHandler.GetOne)
+	socialMedia.POST("", socialMediaHandler.Create)
+	socialMedia.PUT("/:id", socialMediaAuthorization, socialMediaHandler.Update)
+	socialMedia.DELETE("/:id", socialMediaAuthorization, socialMediaHandler.Delete)
+
+	// Admin endpoints
+	admin := router.Group("/admin", authentication)
+	admin.GET("/users", middleware.RequirePermission(core.PermissionManageUsers), adminHandler.GetUsers)
+	admin.PUT("/users/:id/role", middleware.RequirePermission(core.PermissionManageUsers), adminHandler.SetRole)
+	admin.POST("/lockouts/unlock", middleware.RequirePermission(core.PermissionUnlockAccounts), adminHandler.Unlock)
+
+	return router
+}
+
+// MetricsHandler serves /metrics for the admin listener, which is kept off the API port.
+func MetricsHandler(m *metrics.Metrics) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	return mux
+}
@@ -0,0 +1,31 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"finalproject/problem"
+)
+
+func TestMalformedJSONIsAProblem(t *testing.T) {
+	router, _ := newTestRouter(t)
+	register(t, router, "jane@example.com", "secret1")
+	token := login(t, router, "jane@example.com", "secret1")["token"].(string)
+
+	for _, path := range []string{"/login", "/register", "/auth/password/forgot", "/auth/refresh", "/photos"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"email": `))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var body map[string]any
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if w.Code != http.StatusBadRequest || w.Header().Get("Content-Type") != problem.ContentType || body["code"] != "invalid_request_body" {
+			t.Errorf("%s: got %d %q %s", path, w.Code, w.Header().Get("Content-Type"), w.Body)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"finalproject/core"
+	"finalproject/middleware"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SocialMediaHandler struct {
+	socialMedia *service.SocialMediaService
+}
+
+func NewSocialMediaHandler(socialMedia *service.SocialMediaService) *SocialMediaHandler {
+	return &SocialMediaHandler{socialMedia: socialMedia}
+}
+
+func (h *SocialMediaHandler) GetAll(c *gin.Context) {
+	// 1. Find all social media data
+	socialMediaData, err := h.socialMedia.FindAll(c.Request.Context())
+	if err != nil {
+		respondInternalError(c, err, "social_media_list_failed")
+		return
+	}
+
+	// 2. Send successful response with all social media data
+	c.JSON(http.StatusOK, newSocialMediaResponses(socialMediaData))
+}
+
+func (h *SocialMediaHandler) GetOne(c *gin.Context) {
+	// 1. Get social media ID from URL parameter
+	socialMediaID, ok := parseID(c, "social_media")
+	if !ok {
+		return
+	}
+
+	// 2. Find social media data by ID
+	socialMediaData, err := h.socialMedia.FindByPublicID(c.Request.Context(), socialMediaID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "social_media_not_found")
+		} else {
+			respondInternalError(c, err, "social_media_find_failed")
+		}
+		return
+	}
+
+	// 3. Send successful response with the social media data
+	c.JSON(http.StatusOK, newSocialMediaResponse(socialMediaData))
+}
+
+// SocialMediaCreate lists the fields a client may set when adding a social media entry.
+type SocialMediaCreate struct {
+	Name           string `json:"name"`
+	SocialMediaURL string `json:"socialMediaUrl"`
+}
+
+func (h *SocialMediaHandler) Create(c *gin.Context) {
+	// 1. Parse request body
+	var request SocialMediaCreate
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. Validate social media data
+	newSocialMediaData := core.SocialMedia{
+		Name:           request.Name,
+		SocialMediaURL: request.SocialMediaURL,
+		UserID:         middleware.CurrentUser(c).ID, // Social media entries always belong to the caller
+	}
+	if err := newSocialMediaData.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// 3. Save social media data in database
+	if err := h.socialMedia.Create(c.Request.Context(), &newSocialMediaData); err != nil {
+		respondInternalError(c, err, "social_media_create_failed")
+		return
+	}
+	currentUser := middleware.CurrentUser(c)
+	newSocialMediaData.User = &currentUser
+
+	// 4. Send successful creation response
+	c.JSON(http.StatusCreated, newSocialMediaResponse(newSocialMediaData))
+}
+
+// SocialMediaUpdate replaces the editable fields of a social media entry.
+type SocialMediaUpdate struct {
+	Name           string `json:"name"`
+	SocialMediaURL string `json:"socialMediaUrl"`
+}
+
+func (h *SocialMediaHandler) Update(c *gin.Context) {
+	// 1. Get social media ID from URL parameter
+	socialMediaID, ok := parseID(c, "social_media")
+	if !ok {
+		return
+	}
+
+	// 2. Parse request body
+	var updatedSocialMediaData SocialMediaUpdate
+	if err := c.ShouldBindJSON(&updatedSocialMediaData); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 3. Find social media data by ID
+	socialMediaData, err := h.socialMedia.FindByPublicID(c.Request.Context(), socialMediaID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "social_media_not_found")
+		} else {
+			respondInternalError(c, err, "social_media_find_failed")
+		}
+		return
+	}
+
+	// 4. Apply the editable fields and validate the result
+	socialMediaData.Name = updatedSocialMediaData.Name
+	socialMediaData.SocialMediaURL = updatedSocialMediaData.SocialMediaURL
+	if err := socialMediaData.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// 5. Save updated social media data in database
+	if err := h.socialMedia.Update(c.Request.Context(), &socialMediaData); err != nil {
+		respondInternalError(c, err, "social_media_update_failed")
+		return
+	}
+
+	// 6. Send successful update response
+	c.JSON(http.StatusOK, newSocialMediaResponse(socialMediaData))
+}
+
+func (h *SocialMediaHandler) Delete(c *gin.Context) {
+	// 1. Get social media ID from URL parameter
+	socialMediaID, ok := parseID(c, "social_media")
+	if !ok {
+		return
+	}
+
+	// 2. Find social media data by ID
+	socialMediaData, err := h.socialMedia.FindByPublicID(c.Request.Context(), socialMediaID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "social_media_not_found")
+		} else {
+			respondInternalError(c, err, "social_media_find_failed")
+		}
+		return
+	}
+
+	// 3. Delete social media data from database
+	if err := h.socialMedia.Delete(c.Request.Context(), &socialMediaData); err != nil {
+		respondInternalError(c, err, "social_media_delete_failed")
+		return
+	}
+
+	// 4. Send successful delete response
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "social_media_deleted")})
+}
@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+
+	"finalproject/config"
+	"finalproject/repository/memory"
+	"finalproject/repository/postgres"
+	"finalproject/repository/traced"
+	"finalproject/tracing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRequestSpansNestUnderTheServerSpan(t *testing.T) {
+	shutdown, err := tracing.Setup(context.Background(), config.TracingConfig{Exporter: "memory", ServiceName: "mygram", SampleRatio: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { shutdown(context.Background()) })
+
+	// Login attempts go through gorm so the request makes a database span; a dry run never reaches a server
+	db, err := gorm.Open(gormpostgres.Open("postgres://mygram@127.0.0.1:1/mygram"), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tracing.ObserveDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+	repos := memory.NewRepositories()
+	repos.LoginAttempts = postgres.NewRepositories(db).LoginAttempts
+	router := newTestRouterOn(t, traced.Wrap(repos))
+
+	register(t, router, "jane@example.com", "secret1")
+	login(t, router, "jane@example.com", "secret1")
+
+	spans := tracing.Spans()
+	server := findSpan(t, spans, "POST /login")
+	if server.SpanKind != trace.SpanKindServer {
+		t.Errorf("span POST /login has kind %s, want server", server.SpanKind)
+	}
+	if check := findSpan(t, spans, "LoginGuard.Check"); check.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("LoginGuard.Check has parent %s, want the server span %s", check.Parent.SpanID(), server.SpanContext.SpanID())
+	}
+	// The repository span sits between the service and the query
+	query := findSpan(t, spans, "SELECT login_attempts")
+	if !descendsFrom(spans, query, server) {
+		t.Error("the login_attempts query span does not descend from the server span")
+	}
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("no span named %q", name)
+	return tracetest.SpanStub{}
+}
+
+// descendsFrom follows the parents of span up to ancestor.
+func descendsFrom(spans tracetest.SpanStubs, span, ancestor tracetest.SpanStub) bool {
+	byID := make(map[trace.SpanID]tracetest.SpanStub, len(spans))
+	for _, s := range spans {
+		byID[s.SpanContext.SpanID()] = s
+	}
+	for span.Parent.IsValid() {
+		if span.Parent.SpanID() == ancestor.SpanContext.SpanID() {
+			return true
+		}
+		parent, ok := byID[span.Parent.SpanID()]
+		if !ok {
+			return false
+		}
+		span = parent
+	}
+	return false
+}
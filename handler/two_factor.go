@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"finalproject/middleware"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactor  *service.TwoFactorService
+	loginGuard *service.LoginGuard
+}
+
+func NewTwoFactorHandler(twoFactor *service.TwoFactorService, loginGuard *service.LoginGuard) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactor: twoFactor, loginGuard: loginGuard}
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`         // TOTP code from the authenticator app
+	RecoveryCode   string `json:"recoveryCode"` // Used instead of Code when the authenticator is lost
+}
+
+// respondTwoFactorError maps two-factor service errors onto HTTP responses,
+// answering 500 with the fallback message code for anything else.
+func respondTwoFactorError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		respondError(c, http.StatusUnauthorized, "two_factor_code_invalid")
+	case errors.Is(err, service.ErrInvalidChallenge):
+		respondError(c, http.StatusUnauthorized, "two_factor_challenge_bad")
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		respondError(c, http.StatusConflict, "two_factor_already_on")
+	case errors.Is(err, service.ErrTwoFactorNotEnabled):
+		respondError(c, http.StatusConflict, "two_factor_not_enabled")
+	case errors.Is(err, service.ErrTwoFactorNotEnrolled):
+		respondError(c, http.StatusConflict, "two_factor_not_enrolled")
+	default:
+		respondInternalError(c, err, fallback)
+	}
+}
+
+// checkGuard answers 429 and returns false while the account or the client IP is throttled,
+// so TOTP and recovery codes cannot be guessed faster than passwords.
+func (h *TwoFactorHandler) checkGuard(c *gin.Context, email string) bool {
+	if err := h.loginGuard.Check(c.Request.Context(), email, c.ClientIP()); err != nil {
+		respondGuardError(c, err)
+		return false
+	}
+	return true
+}
+
+// recordCode counts a wrong code against the account and the client IP, and clears the account
+// counter once a code is accepted.
+func (h *TwoFactorHandler) recordCode(c *gin.Context, email string, err error) {
+	ctx := c.Request.Context()
+	switch {
+	case err == nil:
+		if err := h.loginGuard.Success(ctx, email); err != nil {
+			slog.ErrorContext(ctx, "failed to reset login attempts", "error", err)
+		}
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		if err := h.loginGuard.Failure(ctx, email, c.ClientIP()); err != nil {
+			slog.ErrorContext(ctx, "failed to record two-factor failure", "error", err)
+		}
+	}
+}
+
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	enrollment, err := h.twoFactor.Enroll(c.Request.Context(), middleware.CurrentUser(c).ID)
+	if err != nil {
+		respondTwoFactorError(c, err, "two_factor_enroll_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	// 1. Parse request body
+	var request TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Code == "" {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. Enable two-factor authentication once the authenticator produces a valid code
+	recoveryCodes, err := h.twoFactor.Confirm(c.Request.Context(), middleware.CurrentUser(c).ID, request.Code)
+	if err != nil {
+		respondTwoFactorError(c, err, "two_factor_enable_failed")
+		return
+	}
+
+	// 3. Recovery codes are only ever shown here
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "two_factor_enabled"), "recoveryCodes": recoveryCodes})
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	// 1. Parse request body
+	var request TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Code == "" {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. A stolen access token must not allow guessing codes
+	user := middleware.CurrentUser(c)
+	if !h.checkGuard(c, user.Email) {
+		return
+	}
+
+	// 3. Disable two-factor authentication after checking a TOTP or recovery code
+	err := h.twoFactor.Disable(c.Request.Context(), user.ID, request.Code)
+	h.recordCode(c, user.Email, err)
+	if err != nil {
+		respondTwoFactorError(c, err, "two_factor_disable_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "two_factor_disabled")})
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	// 1. Parse request body
+	var request TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Code == "" {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. A stolen access token must not allow guessing codes
+	user := middleware.CurrentUser(c)
+	if !h.checkGuard(c, user.Email) {
+		return
+	}
+
+	// 3. Replace the recovery codes after checking a TOTP code
+	recoveryCodes, err := h.twoFactor.RegenerateRecoveryCodes(c.Request.Context(), user.ID, request.Code)
+	h.recordCode(c, user.Email, err)
+	if err != nil {
+		respondTwoFactorError(c, err, "recovery_codes_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": recoveryCodes})
+}
+
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	// 1. Parse request body
+	var request TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.ChallengeToken == "" {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+	code := request.Code
+	if code == "" {
+		code = request.RecoveryCode
+	}
+	if code == "" {
+		respondError(c, http.StatusBadRequest, "two_factor_code_missing")
+		return
+	}
+
+	// 2. Guessing codes counts against the challenged account and the client IP like guessing passwords
+	user, err := h.twoFactor.ChallengeUser(c.Request.Context(), request.ChallengeToken)
+	if err != nil {
+		respondTwoFactorError(c, err, "token_generation_failed")
+		return
+	}
+	if !h.checkGuard(c, user.Email) {
+		return
+	}
+
+	// 3. Exchange the challenge for a session; only now is the login complete
+	tokens, err := h.twoFactor.CompleteChallenge(c.Request.Context(), request.ChallengeToken, code, clientInfo(c))
+	h.recordCode(c, user.Email, err)
+	if err != nil {
+		respondTwoFactorError(c, err, "token_generation_failed")
+		return
+	}
+
+	// 4. Send successful login response
+	c.JSON(http.StatusOK, tokens)
+}
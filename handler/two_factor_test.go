@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"finalproject/helpers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// enableTwoFactor enrolls the logged in user and returns the TOTP secret and recovery codes.
+func enableTwoFactor(t *testing.T, router http.Handler, token string) (string, []string) {
+	t.Helper()
+
+	code, enrollment := request(t, router, http.MethodPost, "/auth/2fa/enroll", token, nil)
+	if code != http.StatusOK {
+		t.Fatalf("enroll: %d %v", code, enrollment)
+	}
+	secret := enrollment["secret"].(string)
+
+	// A code from the previous period, so the current one is still unused for the next step
+	confirmCode, _ := helpers.TOTPCode(secret, time.Now().Add(-helpers.TOTPPeriod))
+	code, body := request(t, router, http.MethodPost, "/auth/2fa/confirm", token, gin.H{"code": confirmCode})
+	if code != http.StatusOK {
+		t.Fatalf("confirm: %d %v", code, body)
+	}
+
+	var recoveryCodes []string
+	for _, recoveryCode := range body["recoveryCodes"].([]any) {
+		recoveryCodes = append(recoveryCodes, recoveryCode.(string))
+	}
+	return secret, recoveryCodes
+}
+
+// challenge logs in with the password and returns the challenge token of the second step.
+func challenge(t *testing.T, router http.Handler, email, password string) string {
+	t.Helper()
+	body := login(t, router, email, password)
+	if body["twoFactorRequired"] != true {
+		t.Fatalf("login did not ask for the second factor: %v", body)
+	}
+	return body["challengeToken"].(string)
+}
+
+func TestTwoFactorChallengeIsSingleUse(t *testing.T) {
+	router, _ := newTestRouter(t)
+	register(t, router, "jane@example.com", "secret1")
+	secret, _ := enableTwoFactor(t, router, login(t, router, "jane@example.com", "secret1")["token"].(string))
+
+	challengeToken := challenge(t, router, "jane@example.com", "secret1")
+	verifyCode, _ := helpers.TOTPCode(secret, time.Now())
+	code, tokens := request(t, router, http.MethodPost, "/auth/2fa/verify", "", gin.H{"challengeToken": challengeToken, "code": verifyCode})
+	if code != http.StatusOK || tokens["token"] == nil {
+		t.Fatalf("verify: %d %v", code, tokens)
+	}
+
+	// A later code cannot complete the same challenge twice
+	nextCode, _ := helpers.TOTPCode(secret, time.Now().Add(helpers.TOTPPeriod))
+	if code, body := request(t, router, http.MethodPost, "/auth/2fa/verify", "", gin.H{"challengeToken": challengeToken, "code": nextCode}); code == http.StatusOK {
+		t.Errorf("challenge was accepted twice: %v", body)
+	}
+}
+
+func TestRecoveryCodesAreHashedAndSingleUse(t *testing.T) {
+	router, repos := newTestRouter(t)
+	ctx := context.Background()
+	register(t, router, "jane@example.com", "secret1")
+	_, recoveryCodes := enableTwoFactor(t, router, login(t, router, "jane@example.com", "secret1")["token"].(string))
+
+	// Stored like passwords, never as a fast unsalted digest
+	user, err := repos.Users.FindByEmail(ctx, "jane@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repos.RecoveryCodes.FindUnusedByUser(ctx, user.ID)
+	if err != nil || len(stored) != len(recoveryCodes) {
+		t.Fatalf("stored recovery codes: %d, %v", len(stored), err)
+	}
+	for _, code := range stored {
+		if !strings.HasPrefix(code.CodeHash, "$argon2id$") {
+			t.Errorf("recovery code hash is not a password hash: %.10s", code.CodeHash)
+		}
+	}
+
+	// Codes are accepted in any case and without the separator, once
+	loose := strings.ToUpper(strings.ReplaceAll(recoveryCodes[3], "-", ""))
+	code, tokens := request(t, router, http.MethodPost, "/auth/2fa/verify", "", gin.H{"challengeToken": challenge(t, router, "jane@example.com", "secret1"), "recoveryCode": loose})
+	if code != http.StatusOK || tokens["token"] == nil {
+		t.Fatalf("verify with a recovery code: %d %v", code, tokens)
+	}
+	code, body := request(t, router, http.MethodPost, "/auth/2fa/verify", "", gin.H{"challengeToken": challenge(t, router, "jane@example.com", "secret1"), "recoveryCode": recoveryCodes[3]})
+	if code == http.StatusOK {
+		t.Errorf("recovery code was accepted twice: %v", body)
+	}
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"finalproject/core"
+	"finalproject/middleware"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	users             *service.UserService
+	auth              *service.AuthService
+	emailVerification *service.EmailVerificationService
+	twoFactor         *service.TwoFactorService
+	loginGuard        *service.LoginGuard
+}
+
+func NewUserHandler(users *service.UserService, auth *service.AuthService, emailVerification *service.EmailVerificationService, twoFactor *service.TwoFactorService, loginGuard *service.LoginGuard) *UserHandler {
+	return &UserHandler{users: users, auth: auth, emailVerification: emailVerification, twoFactor: twoFactor, loginGuard: loginGuard}
+}
+
+// UserRegistration lists the fields a client may set when signing up.
+type UserRegistration struct {
+	Username        string `json:"username"`
+	Email           string `json:"email"`
+	Password        string `json:"password"`
+	Age             int    `json:"age"`
+	ProfileImageURL string `json:"profileImageUrl"`
+	Locale          string `json:"locale"`
+}
+
+func (h *UserHandler) Register(c *gin.Context) {
+	// 1. Parse request body
+	var registration UserRegistration
+	if err := c.ShouldBindJSON(&registration); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+	user := core.User{
+		Username:        registration.Username,
+		Email:           registration.Email,
+		Password:        registration.Password,
+		Age:             registration.Age,
+		ProfileImageURL: registration.ProfileImageURL,
+		Locale:          registration.Locale,
+	}
+
+	// 2. Validate user data, reporting every invalid field
+	if err := user.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// 3. Hash the password and create the user record, rejecting duplicate emails
+	err := h.users.Register(c.Request.Context(), &user)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailTaken) {
+			respondError(c, http.StatusConflict, "email_taken")
+		} else {
+			respondInternalError(c, err, "user_create_failed")
+		}
+		return
+	}
+
+	// 4. Send the verification link; the account exists either way and the user can ask for a new link
+	if err := h.emailVerification.SendVerification(c.Request.Context(), user); err != nil {
+		slog.WarnContext(c.Request.Context(), "failed to send verification email", "user_id", user.PublicID, "error", err)
+	}
+
+	// 5. Send successful registration response
+	c.JSON(http.StatusCreated, gin.H{"message": localize(c, "user_registered")})
+}
+
+type LoginCredentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (h *UserHandler) Login(c *gin.Context) {
+	// 1. Parse request body
+	var credentials LoginCredentials
+	if err := c.ShouldBindJSON(&credentials); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 2. Refuse attempts while the account or the client IP is throttled
+	if err := h.loginGuard.Check(c.Request.Context(), credentials.Email, c.ClientIP()); err != nil {
+		respondGuardError(c, err)
+		return
+	}
+
+	// 3. Verify email and password, upgrading the stored hash when needed
+	user, err := h.users.Authenticate(c.Request.Context(), credentials.Email, credentials.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			if err := h.loginGuard.Failure(c.Request.Context(), credentials.Email, c.ClientIP()); err != nil {
+				slog.ErrorContext(c.Request.Context(), "failed to record login failure", "error", err)
+			}
+			respondError(c, http.StatusUnauthorized, "invalid_credentials")
+		} else {
+			respondInternalError(c, err, "user_find_failed")
+		}
+		return
+	}
+
+	// 4. With two-factor authentication on, the session is only issued by /auth/2fa/verify, and the
+	// account counter is only cleared there, so each new challenge does not restart the guessing budget
+	if user.TwoFactorEnabled() {
+		challenge, err := h.twoFactor.Challenge(c.Request.Context(), user)
+		if err != nil {
+			respondInternalError(c, err, "token_generation_failed")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"twoFactorRequired": true, "challengeToken": challenge})
+		return
+	}
+
+	if err := h.loginGuard.Success(c.Request.Context(), credentials.Email); err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to reset login attempts", "error", err)
+	}
+
+	// 5. Start a session: short-lived access token plus rotating refresh token
+	tokens, err := h.auth.IssueTokens(c.Request.Context(), user, clientInfo(c))
+	if err != nil {
+		respondInternalError(c, err, "token_generation_failed")
+		return
+	}
+
+	// 6. Send successful login response
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *UserHandler) Me(c *gin.Context) {
+	c.JSON(http.StatusOK, newPrivateProfile(middleware.CurrentUser(c)))
+}
+
+func (h *UserHandler) GetOne(c *gin.Context) {
+	// 1. Get user ID from URL parameter
+	userID, ok := parseID(c, "user")
+	if !ok {
+		return
+	}
+
+	// 2. Find user by ID
+	user, err := h.users.FindByPublicID(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "user_not_found")
+		} else {
+			respondInternalError(c, err, "user_find_failed")
+		}
+		return
+	}
+
+	// 3. Only the public profile is visible to other users
+	c.JSON(http.StatusOK, newPublicProfile(user))
+}
+
+// UserUpdate replaces the editable profile fields. A non-empty Email different from the
+// current one starts an email change instead of being applied directly.
+type UserUpdate struct {
+	Username        string `json:"username"`
+	Email           string `json:"email"`
+	Age             int    `json:"age"`
+	ProfileImageURL string `json:"profileImageUrl"`
+	Locale          string `json:"locale"`
+}
+
+func (h *UserHandler) Update(c *gin.Context) {
+	// 1. Get user ID from URL parameter
+	userID, ok := parseID(c, "user")
+	if !ok {
+		return
+	}
+
+	// 2. Parse request body
+	var updatedUserData UserUpdate
+	if err := c.ShouldBindJSON(&updatedUserData); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	// 3. Find user by ID
+	user, err := h.users.FindByPublicID(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "user_not_found")
+		} else {
+			respondInternalError(c, err, "user_find_failed")
+		}
+		return
+	}
+
+	// 4. Apply the editable fields and validate the result, including the requested email
+	user.Username = updatedUserData.Username
+	user.Age = updatedUserData.Age
+	user.ProfileImageURL = updatedUserData.ProfileImageURL
+	user.Locale = updatedUserData.Locale
+	candidate := user
+	if updatedUserData.Email != "" {
+		candidate.Email = updatedUserData.Email
+	}
+	if err := candidate.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// 5. Refuse a taken email before saving anything, so the request never half applies
+	emailChanged := updatedUserData.Email != "" && updatedUserData.Email != user.Email
+	if emailChanged {
+		if err := h.emailVerification.CheckEmailAvailable(c.Request.Context(), updatedUserData.Email); err != nil {
+			if errors.Is(err, service.ErrEmailTaken) {
+				respondError(c, http.StatusConflict, "email_taken")
+			} else {
+				respondInternalError(c, err, "email_update_failed")
+			}
+			return
+		}
+	}
+
+	// 6. Save updated user in database
+	if err := h.users.Update(c.Request.Context(), &user); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUsernameTaken):
+			respondError(c, http.StatusConflict, "username_taken")
+		case errors.Is(err, service.ErrEmailTaken):
+			respondError(c, http.StatusConflict, "email_taken")
+		default:
+			respondInternalError(c, err, "user_update_failed")
+		}
+		return
+	}
+
+	// 7. A new email only replaces the current one once the user confirms it
+	if emailChanged {
+		err := h.emailVerification.RequestEmailChange(c.Request.Context(), user.ID, updatedUserData.Email)
+		if err != nil {
+			if errors.Is(err, service.ErrEmailTaken) {
+				respondError(c, http.StatusConflict, "email_taken")
+			} else {
+				respondInternalError(c, err, "email_update_failed")
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": localize(c, "user_updated_email_pending")})
+		return
+	}
+
+	// 8. Send successful update response
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "user_updated")})
+}
+
+func (h *UserHandler) Delete(c *gin.Context) {
+	// 1. Get user ID from URL parameter
+	userID, ok := parseID(c, "user")
+	if !ok {
+		return
+	}
+
+	// 2. Find user by ID
+	user, err := h.users.FindByPublicID(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, http.StatusNotFound, "user_not_found")
+		} else {
+			respondInternalError(c, err, "user_find_failed")
+		}
+		return
+	}
+
+	// 3. Delete user from database, together with their content unless the policy restricts it
+	if err := h.users.Delete(c.Request.Context(), &user); err != nil {
+		switch {
+		case errors.Is(err, service.ErrLastAdmin):
+			respondError(c, http.StatusConflict, "last_admin_delete")
+		case errors.Is(err, service.ErrHasDependents):
+			respondError(c, http.StatusConflict, "user_has_content")
+		default:
+			respondInternalError(c, err, "user_delete_failed")
+		}
+		return
+	}
+
+	// 4. Send successful delete response
+	c.JSON(http.StatusOK, gin.H{"message": localize(c, "user_deleted")})
+}
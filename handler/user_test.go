@@ -0,0 +1,113 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"finalproject/core"
+	"finalproject/handler"
+	"finalproject/helpers"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoginRehashesLegacyBcryptHash(t *testing.T) {
+	router, repos := newTestRouter(t)
+	ctx := context.Background()
+
+	bcrypt, err := helpers.NewPasswords("bcrypt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := bcrypt.Hash("secret1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Users.Create(ctx, &core.User{Email: "jane@example.com", Username: "jane", Age: 20, Password: legacy}); err != nil {
+		t.Fatal(err)
+	}
+
+	login(t, router, "jane@example.com", "secret1")
+
+	user, err := repos.Users.FindByEmail(ctx, "jane@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(user.Password, "$argon2id$") {
+		t.Errorf("password hash was not upgraded: %.10s", user.Password)
+	}
+	// The upgraded hash verifies the same password
+	login(t, router, "jane@example.com", "secret1")
+}
+
+func TestLoginLocksAccountAfterFailures(t *testing.T) {
+	// Delays start after more failures than the threshold, so only the lockout is exercised
+	router, _ := newTestRouter(t, func(deps *service.Dependencies, _ *handler.RouterConfig) {
+		deps.Lockout = service.LockoutPolicy{FreeAttempts: 100, AccountThreshold: 3, IPThreshold: 100}
+	})
+	register(t, router, "jane@example.com", "secret1")
+
+	for i := 0; i < 3; i++ {
+		code, body := request(t, router, http.MethodPost, "/login", "", gin.H{"email": "jane@example.com", "password": "wrong"})
+		if code != http.StatusUnauthorized {
+			t.Fatalf("failure %d: %d %v", i+1, code, body)
+		}
+	}
+
+	// The right password no longer helps while the account is locked
+	code, body := request(t, router, http.MethodPost, "/login", "", gin.H{"email": "jane@example.com", "password": "secret1"})
+	if code != http.StatusTooManyRequests || body["code"] != "login_locked" {
+		t.Fatalf("locked login: %d %v", code, body)
+	}
+
+	// Other accounts are not affected
+	register(t, router, "john@example.com", "secret1")
+	login(t, router, "john@example.com", "secret1")
+}
+
+// failLoginVia sends a failed login for a different account each time, claiming forwardedFor as the client IP.
+func failLoginVia(t *testing.T, router http.Handler, i int, forwardedFor string) int {
+	t.Helper()
+	body, _ := json.Marshal(gin.H{"email": fmt.Sprintf("user%d@example.com", i), "password": "wrong"})
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestLoginLocksClientIPDespiteForgedForwardedFor(t *testing.T) {
+	policy := service.LockoutPolicy{FreeAttempts: 100, AccountThreshold: 100, IPThreshold: 3}
+	router, _ := newTestRouter(t, func(deps *service.Dependencies, _ *handler.RouterConfig) {
+		deps.Lockout = policy
+	})
+
+	// Without trusted proxies the header is ignored and every attempt counts against the peer address
+	var codes []int
+	for i := 0; i < 5; i++ {
+		codes = append(codes, failLoginVia(t, router, i, fmt.Sprintf("198.51.100.%d", i)))
+	}
+	want := []int{401, 401, 401, 429, 429}
+	if fmt.Sprint(codes) != fmt.Sprint(want) {
+		t.Errorf("rotating X-Forwarded-For: got %v, want %v", codes, want)
+	}
+
+	// Behind a trusted proxy the forwarded address is the client
+	router, _ = newTestRouter(t, func(deps *service.Dependencies, cfg *handler.RouterConfig) {
+		deps.Lockout = policy
+		cfg.TrustedProxies = []string{"192.0.2.0/24"} // httptest requests come from 192.0.2.1
+	})
+	for i := 0; i < 5; i++ {
+		if code := failLoginVia(t, router, i, fmt.Sprintf("198.51.100.%d", i)); code != http.StatusUnauthorized {
+			t.Errorf("client %d behind a trusted proxy: got %d", i, code)
+		}
+	}
+}
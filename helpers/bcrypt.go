@@ -1 +1,68 @@
-package helpers
\ No newline at end of file
+package helpers
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptHasher hashes passwords with bcrypt in the standard "$2a$cost$..." format.
+type BcryptHasher struct {
+	Cost int
+}
+
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{Cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(hashed), err
+}
+
+func (h *BcryptHasher) Matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *BcryptHasher) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < h.Cost
+}
+
+// LegacyBcryptHasher verifies the base64-wrapped bcrypt hashes stored by the first RegisterUser.
+// It never produces new hashes; every match is upgraded to the current hasher.
+type LegacyBcryptHasher struct{}
+
+func (LegacyBcryptHasher) Hash(string) (string, error) {
+	return "", errors.New("legacy bcrypt hashes cannot be created")
+}
+
+func (LegacyBcryptHasher) Matches(encoded string) bool {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	return err == nil && (&BcryptHasher{}).Matches(string(decoded))
+}
+
+func (LegacyBcryptHasher) Verify(encoded, password string) (bool, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false, err
+	}
+	return (&BcryptHasher{}).Verify(string(decoded), password)
+}
+
+func (LegacyBcryptHasher) NeedsRehash(string) bool {
+	return true
+}
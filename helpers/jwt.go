@@ -0,0 +1,375 @@
+package helpers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// AccessTokenTTL is the lifetime of access tokens.
+	AccessTokenTTL = time.Hour
+	// TokenIssuer is the iss claim of every token minted by mygram.
+	TokenIssuer = "mygram"
+	// AccessTokenAudience is the aud claim of access tokens. Other token kinds signed by the ring use
+	// their own audience so they can never be presented as an access token.
+	AccessTokenAudience = "mygram"
+
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Claims is the payload of mygram access tokens.
+type Claims struct {
+	UserID string `json:"user_id"` // Public ID of the user
+	Role   string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// SigningKey is one asymmetric key of the ring, identified by the kid header of the tokens it signs.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	CreatedAt time.Time
+	RetiredAt time.Time // Zero while the key is active
+}
+
+// KeyRing signs tokens with its active key and verifies them with the active key or any retiring key.
+// When Dir is set, keys are persisted there as PKCS#8 PEM files so every instance shares the same ring.
+type KeyRing struct {
+	Algorithm string
+	Dir       string
+
+	mu     sync.RWMutex
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// NewKeyRing loads the keys stored in dir, or generates a fresh active key when there are none.
+// An empty dir keeps keys in memory only, which is fine for a single instance.
+func NewKeyRing(algorithm, dir string) (*KeyRing, error) {
+	if algorithm != AlgorithmRS256 && algorithm != AlgorithmEdDSA {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+
+	k := &KeyRing{Algorithm: algorithm, Dir: dir, keys: make(map[string]*SigningKey)}
+	if err := k.reload(); err != nil {
+		return nil, err
+	}
+	if k.active == nil {
+		if err := k.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// GenerateToken signs an access token carrying the user_id and role claims.
+func (k *KeyRing) GenerateToken(userID, role string) (string, error) {
+	now := time.Now()
+	return k.Sign(Claims{
+		UserID: userID,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer,
+			Audience:  jwt.ClaimStrings{AccessTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+	})
+}
+
+// VerifyToken parses a token produced by GenerateToken and returns its user_id claim.
+func (k *KeyRing) VerifyToken(tokenString string) (string, error) {
+	var claims Claims
+	if err := k.Parse(tokenString, &claims, jwt.WithAudience(AccessTokenAudience)); err != nil {
+		return "", err
+	}
+	if claims.UserID == "" {
+		return "", errors.New("invalid token claims")
+	}
+	return claims.UserID, nil
+}
+
+// Sign signs arbitrary claims with the active key.
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	key := k.active
+	k.mu.RUnlock()
+
+	token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// Parse verifies the signature against the key named by the kid header and decodes the claims.
+// Extra options typically pin the expected audience.
+func (k *KeyRing) Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		k.mu.RLock()
+		key, ok := k.keys[kid]
+		k.mu.RUnlock()
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		if t.Method.Alg() != key.Algorithm {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.Private.Public(), nil
+	}, append([]jwt.ParserOption{jwt.WithIssuer(TokenIssuer), jwt.WithExpirationRequired()}, options...)...)
+	if err != nil || !token.Valid {
+		return errors.New("invalid or expired token")
+	}
+	return nil
+}
+
+// Rotate generates a new active key. The previous key keeps verifying tokens until it is pruned.
+func (k *KeyRing) Rotate() error {
+	key, err := generateSigningKey(k.Algorithm)
+	if err != nil {
+		return err
+	}
+	if k.Dir != "" {
+		if err := writeSigningKey(k.Dir, key); err != nil {
+			return err
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.active != nil {
+		k.active.RetiredAt = key.CreatedAt
+	}
+	k.active = key
+	k.keys[key.ID] = key
+	return nil
+}
+
+// Prune forgets retired keys that can no longer have signed an unexpired token.
+func (k *KeyRing) Prune(retention time.Duration) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for id, key := range k.keys {
+		if !key.RetiredAt.IsZero() && time.Since(key.RetiredAt) > retention {
+			delete(k.keys, id)
+			if k.Dir != "" {
+				os.Remove(filepath.Join(k.Dir, id+".pem"))
+			}
+		}
+	}
+}
+
+// RunRotation rotates the active key once it is older than interval and prunes keys retired for longer
+// than retention, until ctx is cancelled. retention must cover the longest lifetime of any token the
+// ring signs. Keys written by other instances are picked up on every tick.
+func (k *KeyRing) RunRotation(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := k.reload(); err != nil {
+			slog.ErrorContext(ctx, "failed to reload signing keys", "error", err)
+		}
+
+		k.mu.RLock()
+		due := time.Since(k.active.CreatedAt) >= interval
+		k.mu.RUnlock()
+		if due {
+			if err := k.Rotate(); err != nil {
+				slog.ErrorContext(ctx, "failed to rotate signing key", "error", err)
+			}
+		}
+		k.Prune(retention)
+	}
+}
+
+// JWK is the public half of a signing key as published in the JWKS document (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the active and retiring keys, newest first.
+func (k *KeyRing) JWKS() JWKSet {
+	k.mu.RLock()
+	keys := make([]*SigningKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, key)
+	}
+	k.mu.RUnlock()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+
+	set := JWKSet{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch pub := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// reload reads every key in Dir. The newest becomes active; older keys are retired as of their successor's creation.
+func (k *KeyRing) reload() error {
+	if k.Dir == "" {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(k.Dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	var loaded []*SigningKey
+	for _, path := range paths {
+		key, err := readSigningKey(path)
+		if err != nil {
+			return err
+		}
+		loaded = append(loaded, key)
+	}
+	if len(loaded) == 0 {
+		return nil
+	}
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].CreatedAt.Before(loaded[j].CreatedAt) })
+	for i := 0; i < len(loaded)-1; i++ {
+		loaded[i].RetiredAt = loaded[i+1].CreatedAt
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = make(map[string]*SigningKey, len(loaded))
+	for _, key := range loaded {
+		k.keys[key.ID] = key
+	}
+	k.active = loaded[len(loaded)-1]
+	return nil
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	if algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+func generateSigningKey(algorithm string) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return newSigningKey(private, time.Now())
+}
+
+// newSigningKey derives the kid from the SHA-256 of the public key, so every instance computes the same ID.
+func newSigningKey(private crypto.Signer, createdAt time.Time) (*SigningKey, error) {
+	der, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+
+	algorithm := AlgorithmRS256
+	if _, ok := private.(ed25519.PrivateKey); ok {
+		algorithm = AlgorithmEdDSA
+	}
+	return &SigningKey{
+		ID:        base64.RawURLEncoding.EncodeToString(sum[:16]),
+		Algorithm: algorithm,
+		Private:   private,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func writeSigningKey(dir string, key *SigningKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return os.WriteFile(filepath.Join(dir, key.ID+".pem"), data, 0o600)
+}
+
+// readSigningKey loads a PKCS#8 PEM file; the file modification time records when the key was created.
+func readSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: not a PEM file", filepath.Base(path))
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		return newSigningKey(private, info.ModTime())
+	case ed25519.PrivateKey:
+		return newSigningKey(private, info.ModTime())
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T", filepath.Base(path), parsed)
+	}
+}
@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestVerifyTokenRejectsExpiredToken(t *testing.T) {
+	keys, err := NewKeyRing(AlgorithmEdDSA, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issued := time.Now().Add(-2 * AccessTokenTTL)
+	token, err := keys.Sign(Claims{
+		UserID: "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer,
+			Audience:  jwt.ClaimStrings{AccessTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(issued),
+			ExpiresAt: jwt.NewNumericDate(issued.Add(AccessTokenTTL)),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := keys.VerifyToken(token); err == nil {
+		t.Error("expired token was accepted")
+	}
+}
+
+func TestPruneRejectsTokensOfPrunedKeys(t *testing.T) {
+	keys, err := NewKeyRing(AlgorithmRS256, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := keys.GenerateToken("user", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A freshly retired key still verifies the tokens it signed
+	keys.Prune(AccessTokenTTL)
+	if _, err := keys.VerifyToken(token); err != nil {
+		t.Fatalf("token of the retiring key was rejected: %v", err)
+	}
+
+	// Once retired for longer than the retention it is forgotten
+	for _, key := range keys.keys {
+		if !key.RetiredAt.IsZero() {
+			key.RetiredAt = time.Now().Add(-AccessTokenTTL - time.Minute)
+		}
+	}
+	keys.Prune(AccessTokenTTL)
+	if _, err := keys.VerifyToken(token); err == nil {
+		t.Error("token of a pruned key was accepted")
+	}
+	if _, err := keys.GenerateToken("user", ""); err != nil {
+		t.Errorf("active key was pruned: %v", err)
+	}
+}
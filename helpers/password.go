@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PasswordHasher is one password hashing algorithm.
+type PasswordHasher interface {
+	// Hash returns the encoded hash of password, including algorithm and parameters.
+	Hash(password string) (string, error)
+	// Matches reports whether encoded was produced by this algorithm.
+	Matches(encoded string) bool
+	// Verify reports whether password matches encoded.
+	Verify(encoded, password string) (bool, error)
+	// NeedsRehash reports whether encoded uses weaker parameters than the hasher is configured with.
+	NeedsRehash(encoded string) bool
+}
+
+// ErrUnknownHashFormat is returned when no hasher recognizes a stored hash.
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// Passwords hashes new passwords with Current and still verifies hashes produced by any Legacy hasher.
+type Passwords struct {
+	Current PasswordHasher
+	Legacy  []PasswordHasher
+}
+
+// NewPasswords returns the hasher set for the named algorithm ("argon2id" or "bcrypt"). Whichever is not
+// current, plus the base64-wrapped bcrypt format, is kept for verification only.
+func NewPasswords(algorithm string) (*Passwords, error) {
+	switch algorithm {
+	case "", "argon2id":
+		return &Passwords{
+			Current: NewArgon2idHasher(),
+			Legacy:  []PasswordHasher{NewBcryptHasher(0), LegacyBcryptHasher{}},
+		}, nil
+	case "bcrypt":
+		return &Passwords{
+			Current: NewBcryptHasher(0),
+			Legacy:  []PasswordHasher{NewArgon2idHasher(), LegacyBcryptHasher{}},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported password hasher %q", algorithm)
+}
+
+func (p *Passwords) Hash(password string) (string, error) {
+	return p.Current.Hash(password)
+}
+
+// Verify checks password against encoded. rehash is true when the password matched but encoded should be
+// replaced by a fresh Hash, either because it uses a legacy algorithm or outdated parameters.
+func (p *Passwords) Verify(encoded, password string) (ok, rehash bool, err error) {
+	if p.Current.Matches(encoded) {
+		ok, err = p.Current.Verify(encoded, password)
+		return ok, ok && p.Current.NeedsRehash(encoded), err
+	}
+
+	for _, hasher := range p.Legacy {
+		if hasher.Matches(encoded) {
+			ok, err = hasher.Verify(encoded, password)
+			return ok, ok, err
+		}
+	}
+
+	return false, false, ErrUnknownHashFormat
+}
@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPPeriod and TOTPDigits follow the RFC 6238 defaults every authenticator app supports.
+	TOTPPeriod = 30 * time.Second
+	TOTPDigits = 6
+	// TOTPSkew is how many periods before and after the current one are accepted to absorb clock drift.
+	TOTPSkew = 1
+	// RecoveryCodeLength is the length of a normalized recovery code.
+	RecoveryCodeLength = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random 160-bit secret, base32 encoded as authenticator apps expect.
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI returns the otpauth:// URI authenticator apps import, usually by scanning it as a QR code.
+func TOTPURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(TOTPDigits)},
+		"period":    {fmt.Sprint(int(TOTPPeriod.Seconds()))},
+	}
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// TOTPCode returns the code for the period containing t.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	return totpCodeAt(secret, t.Unix()/int64(TOTPPeriod.Seconds()))
+}
+
+// ValidateTOTP checks code against the periods around t and returns the matching time step.
+// Callers should reject steps not greater than the last accepted one so a code cannot be replayed.
+func ValidateTOTP(secret, code string, t time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	current := t.Unix() / int64(TOTPPeriod.Seconds())
+	for step := current - TOTPSkew; step <= current+TOTPSkew; step++ {
+		expected, err := totpCodeAt(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCodeAt implements HOTP (RFC 4226) over the time step counter.
+func totpCodeAt(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", TOTPDigits, value%1000000), nil
+}
+
+// GenerateRecoveryCode returns a random one-time code formatted as xxxxx-xxxxx.
+func GenerateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(totpEncoding.EncodeToString(b))[:RecoveryCodeLength]
+	return code[:RecoveryCodeLength/2] + "-" + code[RecoveryCodeLength/2:], nil
+}
+
+// NormalizeRecoveryCode lowercases a code and drops separators so users can type it loosely.
+func NormalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+}
@@ -0,0 +1,52 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed of the RFC 6238 appendix B vectors, "12345678901234567890", base32 encoded.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; the 6-digit codes are their last six digits
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, v := range vectors {
+		code, err := TOTPCode(rfc6238Secret, time.Unix(v.unix, 0))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d): %v", v.unix, err)
+		}
+		if code != v.code {
+			t.Errorf("TOTPCode(%d) = %s, want %s", v.unix, code, v.code)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / int64(TOTPPeriod.Seconds())
+
+	for offset := -TOTPSkew; offset <= TOTPSkew; offset++ {
+		code, _ := TOTPCode(rfc6238Secret, now.Add(time.Duration(offset)*TOTPPeriod))
+		matched, ok := ValidateTOTP(rfc6238Secret, code, now)
+		if !ok || matched != step+int64(offset) {
+			t.Errorf("code %d periods away: got step %d, %v; want step %d", offset, matched, ok, step+int64(offset))
+		}
+	}
+
+	stale, _ := TOTPCode(rfc6238Secret, now.Add(-(TOTPSkew+1)*TOTPPeriod))
+	if _, ok := ValidateTOTP(rfc6238Secret, stale, now); ok {
+		t.Error("code outside the skew was accepted")
+	}
+}
@@ -0,0 +1,132 @@
+package i18n
+
+// catalog maps message codes to their translations. Codes are part of the API and must not change;
+// add a new code rather than repurposing an old one.
+var catalog = map[string]map[Locale]string{
+	// Generic problems
+	"internal_error": {EN: "An unexpected error occurred", ID: "Terjadi kesalahan tak terduga"},
+	"not_found":      {EN: "The requested resource was not found", ID: "Sumber daya yang diminta tidak ditemukan"},
+	"conflict":       {EN: "The request conflicts with existing data", ID: "Permintaan bertentangan dengan data yang ada"},
+
+	// Requests
+	"invalid_request_body": {EN: "Invalid request body", ID: "Isi permintaan tidak valid"},
+	"validation_failed":    {EN: "Validation failed", ID: "Validasi gagal"},
+
+	// Authentication and authorization
+	"missing_bearer_token":      {EN: "Missing or malformed bearer token", ID: "Token bearer tidak ada atau tidak valid"},
+	"invalid_token":             {EN: "Invalid or expired token", ID: "Token tidak valid atau kedaluwarsa"},
+	"user_no_longer_exists":     {EN: "User no longer exists", ID: "Pengguna sudah tidak ada"},
+	"forbidden":                 {EN: "You are not allowed to access this resource", ID: "Anda tidak diizinkan mengakses sumber daya ini"},
+	"forbidden_user":            {EN: "You are not allowed to access this user", ID: "Anda tidak diizinkan mengakses pengguna ini"},
+	"forbidden_photo":           {EN: "You are not allowed to access this photo", ID: "Anda tidak diizinkan mengakses foto ini"},
+	"forbidden_comment":         {EN: "You are not allowed to access this comment", ID: "Anda tidak diizinkan mengakses komentar ini"},
+	"forbidden_social_media":    {EN: "You are not allowed to access this social media", ID: "Anda tidak diizinkan mengakses media sosial ini"},
+	"invalid_credentials":       {EN: "Invalid email or password", ID: "Email atau password salah"},
+	"token_generation_failed":   {EN: "Failed to generate token", ID: "Gagal membuat token"},
+	"invalid_refresh_token":     {EN: "Invalid or expired refresh token", ID: "Refresh token tidak valid atau kedaluwarsa"},
+	"refresh_token_reused":      {EN: "Refresh token reuse detected, session revoked", ID: "Refresh token dipakai ulang, sesi dicabut"},
+	"refresh_failed":            {EN: "Failed to refresh token", ID: "Gagal memperbarui token"},
+	"logout_failed":             {EN: "Failed to log out", ID: "Gagal keluar"},
+	"logged_out":                {EN: "Logged out successfully", ID: "Berhasil keluar"},
+	"sessions_failed":           {EN: "Failed to get sessions", ID: "Gagal mengambil sesi"},
+	"session_revoke_failed":     {EN: "Failed to revoke session", ID: "Gagal mencabut sesi"},
+	"session_revoked":           {EN: "Session revoked successfully", ID: "Sesi berhasil dicabut"},
+	"login_check_failed":        {EN: "Failed to check login attempts", ID: "Gagal memeriksa percobaan masuk"},
+	"login_throttled":           {EN: "Too many failed attempts, slow down", ID: "Terlalu banyak percobaan gagal, coba lagi nanti"},
+	"login_locked":              {EN: "Too many failed attempts, temporarily locked", ID: "Terlalu banyak percobaan gagal, akun dikunci sementara"},
+	"password_reset_throttled":  {EN: "Too many password reset requests, try again later", ID: "Terlalu banyak permintaan reset kata sandi, coba lagi nanti"},
+	"email_resend_throttled":    {EN: "Too many verification emails requested, try again later", ID: "Terlalu banyak permintaan email verifikasi, coba lagi nanti"},
+	"email_not_verified":        {EN: "Verify your email address before posting photos", ID: "Verifikasi alamat email Anda sebelum mengunggah foto"},
+	"invalid_role":              {EN: "Role must be one of user, moderator or admin", ID: "Peran harus salah satu dari user, moderator atau admin"},
+	"last_admin_demote":         {EN: "Cannot demote the last admin", ID: "Admin terakhir tidak dapat diturunkan"},
+	"last_admin_delete":         {EN: "Cannot delete the last admin", ID: "Admin terakhir tidak dapat dihapus"},
+	"user_has_content":          {EN: "Delete the user's photos, comments and social media first", ID: "Hapus foto, komentar, dan media sosial pengguna terlebih dahulu"},
+	"photo_has_comments":        {EN: "Delete the photo's comments first", ID: "Hapus komentar pada foto terlebih dahulu"},
+	"role_update_failed":        {EN: "Failed to update role", ID: "Gagal memperbarui peran"},
+	"users_failed":              {EN: "Failed to retrieve users", ID: "Gagal mengambil daftar pengguna"},
+	"unlock_account_failed":     {EN: "Failed to unlock account", ID: "Gagal membuka kunci akun"},
+	"unlock_ip_failed":          {EN: "Failed to unlock IP", ID: "Gagal membuka kunci IP"},
+	"unlocked":                  {EN: "Unlocked successfully", ID: "Berhasil dibuka"},
+	"two_factor_already_on":     {EN: "Two-factor authentication already enabled", ID: "Autentikasi dua faktor sudah aktif"},
+	"two_factor_not_enabled":    {EN: "Two-factor authentication not enabled", ID: "Autentikasi dua faktor belum aktif"},
+	"two_factor_not_enrolled":   {EN: "Start two-factor enrollment first", ID: "Mulai pendaftaran autentikasi dua faktor terlebih dahulu"},
+	"two_factor_code_missing":   {EN: "Missing two-factor code", ID: "Kode dua faktor belum diisi"},
+	"two_factor_code_invalid":   {EN: "Invalid two-factor code", ID: "Kode dua faktor salah"},
+	"two_factor_challenge_bad":  {EN: "Invalid or expired two-factor challenge", ID: "Tantangan dua faktor tidak valid atau kedaluwarsa"},
+	"two_factor_enabled":        {EN: "Two-factor authentication enabled", ID: "Autentikasi dua faktor diaktifkan"},
+	"two_factor_disabled":       {EN: "Two-factor authentication disabled", ID: "Autentikasi dua faktor dinonaktifkan"},
+	"two_factor_enroll_failed":  {EN: "Failed to start two-factor enrollment", ID: "Gagal memulai pendaftaran autentikasi dua faktor"},
+	"two_factor_enable_failed":  {EN: "Failed to enable two-factor authentication", ID: "Gagal mengaktifkan autentikasi dua faktor"},
+	"two_factor_disable_failed": {EN: "Failed to disable two-factor authentication", ID: "Gagal menonaktifkan autentikasi dua faktor"},
+	"recovery_codes_failed":     {EN: "Failed to regenerate recovery codes", ID: "Gagal membuat ulang kode pemulihan"},
+
+	// Password reset and email verification
+	"reset_link_sent":            {EN: "If the email is registered, a reset link has been sent", ID: "Jika email terdaftar, tautan reset telah dikirim"},
+	"invalid_reset_token":        {EN: "Invalid or expired reset token", ID: "Token reset tidak valid atau kedaluwarsa"},
+	"password_reset_failed":      {EN: "Failed to reset password", ID: "Gagal mereset password"},
+	"password_reset":             {EN: "Password reset successfully", ID: "Password berhasil direset"},
+	"invalid_verification_link":  {EN: "Invalid or expired verification link", ID: "Tautan verifikasi tidak valid atau kedaluwarsa"},
+	"email_verify_failed":        {EN: "Failed to verify email", ID: "Gagal memverifikasi email"},
+	"email_verified":             {EN: "Email verified successfully", ID: "Email berhasil diverifikasi"},
+	"email_already_verified":     {EN: "Email already verified", ID: "Email sudah diverifikasi"},
+	"verification_email_failed":  {EN: "Failed to send verification email", ID: "Gagal mengirim email verifikasi"},
+	"verification_email_sent":    {EN: "Verification email sent", ID: "Email verifikasi telah dikirim"},
+	"email_taken":                {EN: "Email already exists", ID: "Email sudah terdaftar"},
+	"username_taken":             {EN: "Username already exists", ID: "Username sudah digunakan"},
+	"email_update_failed":        {EN: "Failed to update email", ID: "Gagal memperbarui email"},
+	"user_registered":            {EN: "User registered successfully, check your email to verify your address", ID: "Pendaftaran berhasil, periksa email Anda untuk verifikasi"},
+	"user_updated":               {EN: "User updated successfully", ID: "Pengguna berhasil diperbarui"},
+	"user_updated_email_pending": {EN: "User updated successfully, check your new email to confirm the change", ID: "Pengguna berhasil diperbarui, periksa email baru Anda untuk konfirmasi"},
+	"user_deleted":               {EN: "User deleted successfully", ID: "Pengguna berhasil dihapus"},
+	"user_create_failed":         {EN: "Failed to create user", ID: "Gagal membuat pengguna"},
+	"user_update_failed":         {EN: "Failed to update user", ID: "Gagal memperbarui pengguna"},
+	"user_delete_failed":         {EN: "Failed to delete user", ID: "Gagal menghapus pengguna"},
+	"photo_create_failed":        {EN: "Failed to create photo", ID: "Gagal membuat foto"},
+	"photo_update_failed":        {EN: "Failed to update photo", ID: "Gagal memperbarui foto"},
+	"photo_delete_failed":        {EN: "Failed to delete photo", ID: "Gagal menghapus foto"},
+	"photo_deleted":              {EN: "Photo deleted successfully", ID: "Foto berhasil dihapus"},
+	"photos_failed":              {EN: "Failed to get photos", ID: "Gagal mengambil foto"},
+	"comment_create_failed":      {EN: "Failed to create comment", ID: "Gagal membuat komentar"},
+	"comment_update_failed":      {EN: "Failed to update comment", ID: "Gagal memperbarui komentar"},
+	"comment_delete_failed":      {EN: "Failed to delete comment", ID: "Gagal menghapus komentar"},
+	"comment_deleted":            {EN: "Comment deleted successfully", ID: "Komentar berhasil dihapus"},
+	"comments_failed":            {EN: "Failed to get comments", ID: "Gagal mengambil komentar"},
+	"social_media_create_failed": {EN: "Failed to create social media data", ID: "Gagal membuat data media sosial"},
+	"social_media_update_failed": {EN: "Failed to update social media data", ID: "Gagal memperbarui data media sosial"},
+	"social_media_delete_failed": {EN: "Failed to delete social media data", ID: "Gagal menghapus data media sosial"},
+	"social_media_deleted":       {EN: "Social media data deleted successfully", ID: "Data media sosial berhasil dihapus"},
+	"social_media_list_failed":   {EN: "Failed to get social media data", ID: "Gagal mengambil data media sosial"},
+
+	// Resource lookups, one set per resource key
+	"missing_user_id":          {EN: "Missing user ID", ID: "ID pengguna belum diisi"},
+	"invalid_user_id":          {EN: "Invalid user ID", ID: "ID pengguna tidak valid"},
+	"user_not_found":           {EN: "User not found", ID: "Pengguna tidak ditemukan"},
+	"user_find_failed":         {EN: "Failed to find user", ID: "Gagal mencari pengguna"},
+	"missing_photo_id":         {EN: "Missing photo ID", ID: "ID foto belum diisi"},
+	"invalid_photo_id":         {EN: "Invalid photo ID", ID: "ID foto tidak valid"},
+	"photo_not_found":          {EN: "Photo not found", ID: "Foto tidak ditemukan"},
+	"photo_find_failed":        {EN: "Failed to find photo", ID: "Gagal mencari foto"},
+	"missing_comment_id":       {EN: "Missing comment ID", ID: "ID komentar belum diisi"},
+	"invalid_comment_id":       {EN: "Invalid comment ID", ID: "ID komentar tidak valid"},
+	"comment_not_found":        {EN: "Comment not found", ID: "Komentar tidak ditemukan"},
+	"comment_find_failed":      {EN: "Failed to find comment", ID: "Gagal mencari komentar"},
+	"missing_social_media_id":  {EN: "Missing social media ID", ID: "ID media sosial belum diisi"},
+	"invalid_social_media_id":  {EN: "Invalid social media ID", ID: "ID media sosial tidak valid"},
+	"social_media_not_found":   {EN: "Social media data not found", ID: "Data media sosial tidak ditemukan"},
+	"social_media_find_failed": {EN: "Failed to get social media data", ID: "Gagal mengambil data media sosial"},
+	"missing_session_id":       {EN: "Missing session ID", ID: "ID sesi belum diisi"},
+	"session_not_found":        {EN: "Session not found", ID: "Sesi tidak ditemukan"},
+
+	// Validation rules; the first argument is the field name
+	"validation.required": {EN: "%s is required", ID: "%s harus diisi"},
+	"validation.email":    {EN: "%s is not a valid email address", ID: "%s tidak valid"},
+	"validation.min":      {EN: "%s must be at least %s", ID: "%s minimal %s"},
+	"validation.min_len":  {EN: "%s must be at least %s characters", ID: "%s minimal harus memiliki %s karakter"},
+	"validation.max":      {EN: "%s must be at most %s", ID: "%s maksimal %s"},
+	"validation.max_len":  {EN: "%s must be at most %s characters", ID: "%s maksimal %s karakter"},
+	"validation.oneof":    {EN: "%s must be one of: %s", ID: "%s harus salah satu dari: %s"},
+	"validation.weburl":   {EN: "%s must be an http or https URL", ID: "%s harus berupa URL http atau https"},
+	"validation.username": {EN: "%s may only contain 3-30 letters, digits, dots or underscores", ID: "%s hanya boleh berisi 3-30 huruf, angka, titik atau garis bawah"},
+	"validation.age":      {EN: "%s must be between %d and %d", ID: "%s harus antara %d dan %d"},
+	"validation.invalid":  {EN: "%s is invalid", ID: "%s tidak valid"},
+}
@@ -0,0 +1,87 @@
+// Package i18n holds the message catalog. Every message has a stable code that clients can rely on,
+// and a translation per supported locale.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a supported language, identified by its ISO 639-1 code.
+type Locale string
+
+const (
+	EN Locale = "en"
+	ID Locale = "id"
+
+	// DefaultLocale is used when neither the user nor the request asks for a supported locale.
+	DefaultLocale = EN
+)
+
+// Supported lists the locales with a complete catalog.
+var Supported = []Locale{EN, ID}
+
+// Parse returns the supported locale matching tag (e.g. "id", "en-US"), if any.
+func Parse(tag string) (Locale, bool) {
+	base, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	locale := Locale(strings.ToLower(base))
+	for _, supported := range Supported {
+		if locale == supported {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the supported locale the Accept-Language header prefers most,
+// falling back to DefaultLocale.
+func Negotiate(acceptLanguage string) Locale {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+
+	for _, candidate := range candidates {
+		if locale, ok := Parse(candidate.tag); ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// T formats the message with the given code in locale, falling back to DefaultLocale and
+// finally to the code itself when no translation exists.
+func T(locale Locale, code string, args ...any) string {
+	translations, ok := catalog[code]
+	if !ok {
+		return code
+	}
+	format, ok := translations[locale]
+	if !ok {
+		format = translations[DefaultLocale]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
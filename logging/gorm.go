@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger sends gorm's logs to slog with the context of the query, so a query can be traced back
+// to its request. Failed queries are logged as errors and slow ones as warnings; every query is
+// logged at debug level when queries is set. SQL is logged with placeholders, never with its arguments.
+type GormLogger struct {
+	logger        *slog.Logger
+	slowThreshold time.Duration
+	queries       bool
+}
+
+func NewGormLogger(logger *slog.Logger, slowThreshold time.Duration, queries bool) *GormLogger {
+	return &GormLogger{logger: logger, slowThreshold: slowThreshold, queries: queries}
+}
+
+// LogMode is part of gorm's interface; the level is controlled by slog instead.
+func (l *GormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (l *GormLogger) Info(ctx context.Context, format string, args ...any) {
+	l.logger.InfoContext(ctx, fmt.Sprintf(format, args...))
+}
+
+func (l *GormLogger) Warn(ctx context.Context, format string, args ...any) {
+	l.logger.WarnContext(ctx, fmt.Sprintf(format, args...))
+}
+
+func (l *GormLogger) Error(ctx context.Context, format string, args ...any) {
+	l.logger.ErrorContext(ctx, fmt.Sprintf(format, args...))
+}
+
+// ParamsFilter drops the query arguments, which hold emails, password hashes and tokens.
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	return sql, nil
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+
+	level, message := slog.LevelDebug, "query"
+	switch {
+	case err != nil && !expected(err):
+		level, message = slog.LevelError, "query failed"
+	case slow:
+		level, message = slog.LevelWarn, "slow query"
+	case !l.queries:
+		return
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	l.logger.LogAttrs(ctx, level, message, attrs...)
+}
+
+// expected reports errors the repositories turn into results, such as a missing row or a duplicate,
+// which would otherwise flood the log.
+func expected(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) ||
+		errors.Is(err, gorm.ErrDuplicatedKey) ||
+		errors.Is(err, gorm.ErrForeignKeyViolated)
+}
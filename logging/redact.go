@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// secretKeys name attributes whose values are never logged.
+var secretKeys = map[string]bool{
+	"password":      true,
+	"new_password":  true,
+	"token":         true,
+	"refresh_token": true,
+	"secret":        true,
+	"totp_code":     true,
+	"recovery_code": true,
+	"authorization": true,
+	"cookie":        true,
+}
+
+// emailPattern finds email addresses inside free text such as error messages.
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// redact is the ReplaceAttr hook of every handler: secrets are dropped and email addresses masked,
+// whether they are an attribute of their own or part of a message or error.
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if secretKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, "[REDACTED]")
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, RedactEmails(value.String()))
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, RedactEmails(err.Error()))
+		}
+	}
+	return attr
+}
+
+// RedactEmails masks every email address in s, keeping the first character and the domain,
+// e.g. "jane@example.com" becomes "j***@example.com".
+func RedactEmails(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, MaskEmail)
+}
+
+// MaskEmail keeps the first character of the local part and the domain of email.
+func MaskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return "***"
+	}
+	return local[:1] + "***@" + domain
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// FileMailer writes every email as an .eml file into a directory instead of delivering it,
+// for development and tests without a mail server.
+type FileMailer struct {
+	dir  string
+	from string
+	seq  atomic.Int64
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	if dir == "" {
+		dir = "mail"
+	}
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	// Timestamp first so the files sort by delivery order; recipient last so tests can glob for it
+	name := fmt.Sprintf("%s-%04d-%s.eml",
+		time.Now().UTC().Format("20060102T150405.000000000"),
+		m.seq.Add(1),
+		strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To),
+	)
+	return os.WriteFile(filepath.Join(m.dir, name), render(m.from, msg), 0o644)
+}
+
+// Dir returns the directory emails are written to.
+func (m *FileMailer) Dir() string {
+	return m.dir
+}
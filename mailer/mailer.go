@@ -0,0 +1,29 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer named by driver: "smtp" or "file". An empty driver means "file".
+func New(driver string, smtpConfig SMTPConfig, dir string) (Mailer, error) {
+	switch driver {
+	case "smtp":
+		return NewSMTPMailer(smtpConfig), nil
+	case "", "file":
+		return NewFileMailer(dir, smtpConfig.From), nil
+	}
+	return nil, fmt.Errorf("unsupported mailer %q", driver)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"finalproject/database"
+)
+
+// purgeDeleted permanently removes rows soft-deleted more than -older-than ago.
+func purgeDeleted(ctx context.Context, app *app, args []string) error {
+	flags := flag.NewFlagSet("purge-deleted", flag.ContinueOnError)
+	olderThan := flags.Duration("older-than", 30*24*time.Hour, "minimum time since the soft delete")
+	dryRun := flags.Bool("dry-run", false, "count the rows without removing them")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *olderThan < 0 {
+		return usageErrorf("-older-than must not be negative")
+	}
+
+	result, err := database.PurgeDeleted(ctx, app.db, time.Now().Add(-*olderThan), *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Purged"
+	if *dryRun {
+		verb = "Would purge"
+	}
+	fmt.Printf("%s %d users, %d photos, %d comments and %d social media accounts\n",
+		verb, result.Users, result.Photos, result.Comments, result.SocialMedia)
+	return nil
+}
+
+// reindex rebuilds the indexes of the given tables, or of every table when none are given.
+func reindex(ctx context.Context, app *app, args []string) error {
+	flags := flag.NewFlagSet("reindex", flag.ContinueOnError)
+	concurrently := flags.Bool("concurrently", false, "rebuild without blocking writes")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: reindex [-concurrently] [table ...]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	tables := flags.Args()
+	if len(tables) == 0 {
+		tables = database.Tables
+	}
+	for _, table := range tables {
+		if err := database.ReindexTable(ctx, app.db, table, *concurrently); err != nil {
+			return err
+		}
+		fmt.Printf("Reindexed %s\n", table)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package metrics exports Prometheus metrics for HTTP requests, database queries and business events,
+// together with the Go runtime and process metrics, on a registry of its own.
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"finalproject/service"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "mygram"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	queries  *prometheus.HistogramVec
+
+	registrations prometheus.Counter
+	logins        *prometheus.CounterVec
+	photos        prometheus.Counter
+	comments      prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route template.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		queries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Database query duration by operation and table.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+
+		registrations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "registrations_total",
+			Help:      "Accounts registered.",
+		}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Login attempts by result, succeeded or failed.",
+		}, []string{"result"}),
+		photos: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "photos_created_total",
+			Help:      "Photos posted.",
+		}),
+		comments: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "comments_created_total",
+			Help:      "Comments posted.",
+		}),
+	}
+
+	// Both login results are exported from the start, so rates work before the first failure
+	m.logins.WithLabelValues("succeeded")
+	m.logins.WithLabelValues("failed")
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.latency, m.queries,
+		m.registrations, m.logins, m.photos, m.comments,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Record counts a business event; Metrics is the service.EventRecorder of the server.
+func (m *Metrics) Record(ctx context.Context, event service.Event) {
+	switch event {
+	case service.EventRegistered:
+		m.registrations.Inc()
+	case service.EventLoginSucceeded:
+		m.logins.WithLabelValues("succeeded").Inc()
+	case service.EventLoginFailed:
+		m.logins.WithLabelValues("failed").Inc()
+	case service.EventPhotoCreated:
+		m.photos.Inc()
+	case service.EventCommentCreated:
+		m.comments.Inc()
+	}
+}
@@ -0,0 +1,57 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"finalproject/core"
+	"finalproject/metrics"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestMetricsAreExported(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := metrics.New()
+
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/photos/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/photos/01HV5Z8K2XQ4N7M3R9T6WBYC1D", nil))
+
+	m.Record(context.Background(), service.EventLoginSucceeded)
+
+	// A dry run never reaches the server, but the query callbacks still run
+	db, err := gorm.Open(postgres.Open("postgres://mygram@127.0.0.1:1/mygram"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ObserveDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+	db.First(&core.User{})
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, want := range []string{
+		`mygram_http_requests_total{method="GET",route="/photos/:id",status="200"} 1`,
+		`mygram_http_request_duration_seconds_count{method="GET",route="/photos/:id"} 1`,
+		`mygram_logins_total{result="succeeded"} 1`,
+		`mygram_db_query_duration_seconds_count{operation="query",table="users"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+	if strings.Contains(body, "01HV5Z8K2XQ4N7M3R9T6WBYC1D") {
+		t.Error("route label contains the raw path instead of the route template")
+	}
+}
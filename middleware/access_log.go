@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog writes one record per request with its route template, status and latency. The request
+// and user IDs come from the request context. Failed requests are always logged; successful ones are
+// sampled at sampleRate, between 0 and 1. Requests to skipPaths, such as probes, are not logged.
+func AccessLog(logger *slog.Logger, sampleRate float64, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		if skip[c.Request.URL.Path] {
+			return
+		}
+		if status < http.StatusBadRequest && rand.Float64() >= sampleRate {
+			return
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		// Unmatched requests have no route template; their path is logged alone
+		logger.LogAttrs(c.Request.Context(), level, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
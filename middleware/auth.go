@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"finalproject/core"
+	"finalproject/i18n"
+	"finalproject/logging"
+	"finalproject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentUserKey is the context key holding the authenticated core.User.
+const CurrentUserKey = "currentUser"
+
+// Authentication validates the bearer token and loads the caller into the context.
+func Authentication(auth *service.AuthService, users *service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. Extract bearer token from the Authorization header
+		header := c.GetHeader("Authorization")
+		tokenString, found := strings.CutPrefix(header, "Bearer ")
+		if !found || tokenString == "" {
+			abort(c, http.StatusUnauthorized, "missing_bearer_token")
+			return
+		}
+
+		// 2. Verify token signature and expiry
+		userID, err := auth.VerifyAccessToken(tokenString)
+		if err != nil {
+			abort(c, http.StatusUnauthorized, "invalid_token")
+			return
+		}
+
+		// 3. Load the user the token was issued for
+		user, err := users.FindByPublicID(c.Request.Context(), userID)
+		if err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				abort(c, http.StatusUnauthorized, "user_no_longer_exists")
+			} else {
+				abortInternal(c, err, "user_find_failed")
+			}
+			return
+		}
+
+		// 4. The language the user chose takes precedence over Accept-Language
+		if locale, ok := i18n.Parse(user.Locale); ok {
+			c.Set(LocaleKey, locale)
+		}
+
+		c.Set(CurrentUserKey, user)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("user_id", user.PublicID)))
+		c.Next()
+	}
+}
+
+// CurrentUser returns the user stored by Authentication.
+func CurrentUser(c *gin.Context) core.User {
+	return c.MustGet(CurrentUserKey).(core.User)
+}
+
+// UserAuthorization only lets users update or delete their own account,
+// unless their role grants one of the override permissions.
+func UserAuthorization(overrides ...core.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Param("id")
+		if !core.ValidPublicID(userID) {
+			abort(c, http.StatusBadRequest, "invalid_user_id")
+			return
+		}
+
+		currentUser := CurrentUser(c)
+		if currentUser.PublicID != userID && !hasAny(currentUser.Role, overrides) {
+			abort(c, http.StatusForbidden, "forbidden_user")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// PhotoAuthorization only lets the owner, or a role granting one of the overrides, update or delete a photo.
+func PhotoAuthorization(photos *service.PhotoService, overrides ...core.Permission) gin.HandlerFunc {
+	return ownerAuthorization("photo", overrides, func(ctx context.Context, id string) (int64, error) {
+		photo, err := photos.FindByPublicID(ctx, id)
+		return photo.UserID, err
+	})
+}
+
+// CommentAuthorization only lets the owner, or a role granting one of the overrides, update or delete a comment.
+func CommentAuthorization(comments *service.CommentService, overrides ...core.Permission) gin.HandlerFunc {
+	return ownerAuthorization("comment", overrides, func(ctx context.Context, id string) (int64, error) {
+		comment, err := comments.FindByPublicID(ctx, id)
+		return comment.UserID, err
+	})
+}
+
+// SocialMediaAuthorization only lets the owner, or a role granting one of the overrides,
+// update or delete a social media entry.
+func SocialMediaAuthorization(socialMedia *service.SocialMediaService, overrides ...core.Permission) gin.HandlerFunc {
+	return ownerAuthorization("social_media", overrides, func(ctx context.Context, id string) (int64, error) {
+		socialMediaData, err := socialMedia.FindByPublicID(ctx, id)
+		return socialMediaData.UserID, err
+	})
+}
+
+// ownerAuthorization compares the owner returned by findOwner, given the public ID from the URL,
+// with the current user.
+// resource is the key used in message codes, e.g. "photo" for "photo_not_found".
+// Callers whose role grants one of the override permissions may act on any resource.
+func ownerAuthorization(resource string, overrides []core.Permission, findOwner func(ctx context.Context, id string) (int64, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. Parse resource ID from URL parameter
+		id := c.Param("id")
+		if !core.ValidPublicID(id) {
+			abort(c, http.StatusBadRequest, "invalid_"+resource+"_id")
+			return
+		}
+
+		// 2. Look up the owner of the resource
+		ownerID, err := findOwner(c.Request.Context(), id)
+		if err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				abort(c, http.StatusNotFound, resource+"_not_found")
+			} else {
+				abortInternal(c, err, resource+"_find_failed")
+			}
+			return
+		}
+
+		// 3. Reject callers that neither own the resource nor hold an override permission
+		currentUser := CurrentUser(c)
+		if ownerID != currentUser.ID && !hasAny(currentUser.Role, overrides) {
+			abort(c, http.StatusForbidden, "forbidden_"+resource)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"finalproject/i18n"
+	"finalproject/problem"
+	"finalproject/repository"
+	"finalproject/service"
+	"finalproject/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors renders the last error recorded with c.Error as application/problem+json.
+// Domain errors map onto their status codes; anything unrecognized becomes a generic 500
+// and is only logged.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		body := newProblem(c, err)
+		if body.Status >= http.StatusInternalServerError {
+			slog.ErrorContext(c.Request.Context(), "request failed", "code", body.Code, "error", err)
+		}
+		if body.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(body.RetryAfter))
+		}
+
+		c.Header("Content-Type", problem.ContentType)
+		c.JSON(body.Status, body)
+	}
+}
+
+// Recovery turns panics into a 500 problem response instead of an empty body. The stack trace is
+// kept in the error, so Errors logs it as part of the request's record rather than as raw text.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		c.Error(fmt.Errorf("panic: %v\n%s", recovered, debug.Stack()))
+		c.Abort()
+	})
+}
+
+func newProblem(c *gin.Context, err error) problem.Problem {
+	locale := Locale(c)
+	body := problem.Problem{
+		Status:    http.StatusInternalServerError,
+		Code:      "internal_error",
+		Instance:  c.Request.URL.Path,
+		RequestID: GetRequestID(c),
+	}
+
+	var appErr *problem.Error
+	var fieldErrors validation.Errors
+	var throttled *service.ThrottledError
+	switch {
+	case errors.As(err, &appErr):
+		body.Status, body.Code = appErr.Status, appErr.Code
+	case errors.As(err, &fieldErrors):
+		body.Status, body.Code = http.StatusBadRequest, "validation_failed"
+		body.Errors = fieldErrors.Localize(locale)
+	case errors.As(err, &throttled):
+		body.Status, body.Code = http.StatusTooManyRequests, "login_throttled"
+		if throttled.Locked {
+			body.Code = "login_locked"
+		}
+		switch {
+		case errors.Is(throttled, service.ErrResetThrottled):
+			body.Code = "password_reset_throttled"
+		case errors.Is(throttled, service.ErrVerificationThrottled):
+			body.Code = "email_resend_throttled"
+		}
+		body.RetryAfter = int(math.Ceil(throttled.RetryAfter.Seconds()))
+	case errors.Is(err, repository.ErrNotFound):
+		body.Status, body.Code = http.StatusNotFound, "not_found"
+	case errors.Is(err, repository.ErrDuplicate), errors.Is(err, repository.ErrReferenced):
+		body.Status, body.Code = http.StatusConflict, "conflict"
+	}
+
+	body.Type = problem.TypeURI(body.Code)
+	body.Title = http.StatusText(body.Status)
+	body.Detail = i18n.T(locale, body.Code)
+	return body
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"finalproject/i18n"
+	"finalproject/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleKey is the context key holding the i18n.Locale responses are rendered in.
+const LocaleKey = "locale"
+
+// Localization picks the response locale from the Accept-Language header.
+// Authentication later replaces it with the locale the user chose, if any.
+func Localization() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(LocaleKey, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// Locale returns the locale chosen for the request.
+func Locale(c *gin.Context) i18n.Locale {
+	if locale, ok := c.Get(LocaleKey); ok {
+		return locale.(i18n.Locale)
+	}
+	return i18n.Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// abort stops the chain, leaving Errors to render the problem for status and code.
+func abort(c *gin.Context, status int, code string) {
+	c.Error(problem.New(status, code))
+	c.Abort()
+}
+
+// abortInternal stops the chain with a 500 for code, keeping err for the logs.
+func abortInternal(c *gin.Context, err error, code string) {
+	c.Error(problem.Internal(err, code))
+	c.Abort()
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+
+	"finalproject/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader carries the request ID in both directions.
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the context key holding the request ID.
+	RequestIDKey = "requestID"
+)
+
+// validRequestID limits IDs accepted from clients or proxies to something safe to log and echo.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID keeps the X-Request-ID sent by the client or a proxy, or generates one,
+// echoes it in the response and adds it to the request context, so every log record of the request carries it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("request_id", id)))
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID assigned by RequestID, or "" outside of it.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"finalproject/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission only lets callers whose role grants the permission through.
+// It must run after Authentication.
+func RequirePermission(permission core.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CurrentUser(c).Role.Can(permission) {
+			abort(c, http.StatusForbidden, "forbidden")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// hasAny reports whether the role grants at least one of the permissions.
+func hasAny(role core.Role, permissions []core.Permission) bool {
+	for _, permission := range permissions {
+		if role.Can(permission) {
+			return true
+		}
+	}
+	return false
+}
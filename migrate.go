@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"finalproject/database"
+
+	"gorm.io/gorm"
+)
+
+// migrate runs "migrate up", "migrate down [steps]" (one step by default) or "migrate status".
+// status exits with exitPending when migrations are pending, so CI can gate deployments on it.
+func migrate(ctx context.Context, app *app, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: migrate up | down [steps] | status")
+	}
+
+	migrator, err := database.NewMigrator(app.db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return usageErrorf("invalid number of steps %q", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("Rolled back %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		pending := 0
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+		for _, status := range statuses {
+			appliedAt, note := "pending", ""
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			} else {
+				pending++
+			}
+			switch {
+			case status.Modified:
+				note = "modified since applied"
+			case status.Missing:
+				note = "unknown to this binary"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, appliedAt, note)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if pending > 0 {
+			return &exitError{code: exitPending, err: fmt.Errorf("%d pending migrations", pending)}
+		}
+		return nil
+
+	default:
+		return usageErrorf("unknown migrate command %q", args[0])
+	}
+}
+
+// ensureSchema applies pending migrations when autoMigrate is set, and otherwise fails when any are pending.
+// The migration lock makes it safe for several instances to start at once.
+func ensureSchema(ctx context.Context, db *gorm.DB, autoMigrate bool) error {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	if autoMigrate {
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			slog.InfoContext(ctx, "applied migration", "version", migration.Version, "name", migration.Name)
+		}
+		return err
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d pending migrations, run \"migrate up\" or set AUTO_MIGRATE=true", pending)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Package problem defines the error type handlers record with gin's c.Error. The error middleware
+// renders it as an RFC 7807 application/problem+json response.
+package problem
+
+import (
+	"net/http"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// TypeURI returns the problem type URI for a message code.
+func TypeURI(code string) string {
+	return "urn:mygram:problem:" + code
+}
+
+// Error is an error with the HTTP status and catalog code to report it with.
+// Cause is logged but never sent to the client.
+type Error struct {
+	Status int
+	Code   string
+	Cause  error
+}
+
+// New returns an Error for the status and message code.
+func New(status int, code string) *Error {
+	return &Error{Status: status, Code: code}
+}
+
+// Internal returns a 500 Error for code that keeps cause for the logs.
+func Internal(cause error, code string) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: code, Cause: cause}
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Code + ": " + e.Cause.Error()
+	}
+	return e.Code
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Problem is the RFC 7807 response body, extended with the message code, the request ID and,
+// for validation failures, the invalid fields.
+type Problem struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Status     int    `json:"status"`
+	Detail     string `json:"detail"`
+	Instance   string `json:"instance"`
+	Code       string `json:"code"`
+	RequestID  string `json:"requestId,omitempty"`
+	Errors     any    `json:"errors,omitempty"`
+	RetryAfter int    `json:"retryAfter,omitempty"`
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+type LoginAttemptRepository struct {
+	*table[core.LoginAttempt]
+}
+
+func (r *LoginAttemptRepository) FindByKey(ctx context.Context, key string) (core.LoginAttempt, error) {
+	return r.find(func(a *core.LoginAttempt) bool { return a.Key == key })
+}
+
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (core.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, attempt := range r.rows {
+		if attempt.Key == key {
+			if attempt.LastFailureAt.Before(at.Add(-window)) {
+				attempt.Failures = 0
+			}
+			attempt.Failures++
+			attempt.LastFailureAt = at
+			attempt.UpdatedAt = at
+			r.rows[id] = attempt
+			return attempt, nil
+		}
+	}
+
+	r.lastID++
+	attempt := core.LoginAttempt{ID: r.lastID, Key: key, Failures: 1, LastFailureAt: at, CreatedAt: at, UpdatedAt: at}
+	r.rows[attempt.ID] = attempt
+	return attempt, nil
+}
+
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, attempt := range r.rows {
+		if attempt.Key == key {
+			attempt.LockedUntil = &until
+			r.rows[id] = attempt
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	attempt, err := r.FindByKey(ctx, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	return r.Delete(ctx, &attempt)
+}
@@ -0,0 +1,318 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+// NewRepositories returns empty in-memory repositories, useful for running the API without a database.
+// Deletes are hard deletes, and cascades are not atomic across tables.
+func NewRepositories() repository.Repositories {
+	users := newTable(
+		func(u *core.User) (*int64, *time.Time, *time.Time) { return &u.ID, &u.CreatedAt, &u.UpdatedAt },
+		func(a, b *core.User) bool { return a.Email == b.Email || a.Username == b.Username },
+	)
+	photos := newTable(
+		func(p *core.Photo) (*int64, *time.Time, *time.Time) { return &p.ID, &p.CreatedAt, &p.UpdatedAt },
+		nil,
+	)
+	comments := newTable(
+		func(c *core.Comment) (*int64, *time.Time, *time.Time) { return &c.ID, &c.CreatedAt, &c.UpdatedAt },
+		nil,
+	)
+	socialMedia := newTable(
+		func(s *core.SocialMedia) (*int64, *time.Time, *time.Time) { return &s.ID, &s.CreatedAt, &s.UpdatedAt },
+		nil,
+	)
+
+	// Mimic the public IDs, foreign keys and preloads of the gorm repositories
+	users.publicID = func(u *core.User) *string { return &u.PublicID }
+	photos.publicID = func(p *core.Photo) *string { return &p.PublicID }
+	comments.publicID = func(c *core.Comment) *string { return &c.PublicID }
+	socialMedia.publicID = func(s *core.SocialMedia) *string { return &s.PublicID }
+	photos.parents = func(p *core.Photo) bool { return users.exists(p.UserID) }
+	photos.hydrate = func(p *core.Photo) { p.User = users.get(p.UserID) }
+	comments.parents = func(c *core.Comment) bool { return users.exists(c.UserID) && photos.exists(c.PhotoID) }
+	comments.hydrate = func(c *core.Comment) { c.User, c.Photo = users.get(c.UserID), photos.get(c.PhotoID) }
+	socialMedia.parents = func(s *core.SocialMedia) bool { return users.exists(s.UserID) }
+	socialMedia.hydrate = func(s *core.SocialMedia) { s.User = users.get(s.UserID) }
+
+	return repository.Repositories{
+		Users:       &UserRepository{table: users, photos: photos, comments: comments, socialMedia: socialMedia},
+		Photos:      &PhotoRepository{table: photos, comments: comments},
+		Comments:    comments,
+		SocialMedia: socialMedia,
+
+		RefreshTokens: &RefreshTokenRepository{newTable(
+			func(t *core.RefreshToken) (*int64, *time.Time, *time.Time) { return &t.ID, &t.CreatedAt, &t.UpdatedAt },
+			func(a, b *core.RefreshToken) bool { return a.TokenHash == b.TokenHash },
+		)},
+		PasswordResetTokens: &PasswordResetTokenRepository{newTable(
+			func(t *core.PasswordResetToken) (*int64, *time.Time, *time.Time) {
+				return &t.ID, &t.CreatedAt, &t.UpdatedAt
+			},
+			func(a, b *core.PasswordResetToken) bool { return a.TokenHash == b.TokenHash },
+		)},
+		TwoFactorChallenges: &TwoFactorChallengeRepository{newTable(
+			func(c *core.TwoFactorChallenge) (*int64, *time.Time, *time.Time) {
+				return &c.ID, &c.CreatedAt, &c.UpdatedAt
+			},
+			func(a, b *core.TwoFactorChallenge) bool { return a.TokenHash == b.TokenHash },
+		)},
+		RecoveryCodes: &RecoveryCodeRepository{newTable(
+			func(c *core.RecoveryCode) (*int64, *time.Time, *time.Time) { return &c.ID, &c.CreatedAt, &c.UpdatedAt },
+			nil,
+		)},
+		LoginAttempts: &LoginAttemptRepository{newTable(
+			func(a *core.LoginAttempt) (*int64, *time.Time, *time.Time) { return &a.ID, &a.CreatedAt, &a.UpdatedAt },
+			func(a, b *core.LoginAttempt) bool { return a.Key == b.Key },
+		)},
+	}
+}
+
+// table stores rows by ID and mimics the auto-increment and timestamp behavior of the gorm repositories.
+type table[T any] struct {
+	mu     sync.RWMutex
+	lastID int64
+	rows   map[int64]T
+
+	// fields exposes the primary key and timestamps of a row.
+	fields func(row *T) (id *int64, createdAt, updatedAt *time.Time)
+	// publicID exposes the public ID of models embedding core.Model; nil means the model has none.
+	publicID func(row *T) *string
+	// conflicts reports whether two rows violate a unique constraint; nil means no constraints.
+	conflicts func(a, b *T) bool
+	// parents reports whether the rows a row refers to exist; nil means no foreign keys.
+	parents func(row *T) bool
+	// hydrate fills in the associations of a row read from the table; nil means none.
+	hydrate func(row *T)
+}
+
+func newTable[T any](fields func(*T) (*int64, *time.Time, *time.Time), conflicts func(a, b *T) bool) *table[T] {
+	return &table[T]{rows: make(map[int64]T), fields: fields, conflicts: conflicts}
+}
+
+// FindAll and the other methods ignore the context; there is nothing to cancel or trace in memory.
+func (t *table[T]) FindAll(ctx context.Context) ([]T, error) {
+	return t.all(), nil
+}
+
+// all returns every row in ID order, with associations hydrated.
+func (t *table[T]) all() []T {
+	t.mu.RLock()
+	ids := make([]int64, 0, len(t.rows))
+	for id := range t.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows := make([]T, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, t.rows[id])
+	}
+	t.mu.RUnlock()
+
+	// Associations are read after releasing the lock, so tables may refer to each other
+	if t.hydrate != nil {
+		for i := range rows {
+			t.hydrate(&rows[i])
+		}
+	}
+	return rows
+}
+
+func (t *table[T]) FindByID(ctx context.Context, id int64) (T, error) {
+	t.mu.RLock()
+	row, ok := t.rows[id]
+	t.mu.RUnlock()
+
+	if !ok {
+		return row, repository.ErrNotFound
+	}
+	if t.hydrate != nil {
+		t.hydrate(&row)
+	}
+	return row, nil
+}
+
+func (t *table[T]) FindByPublicID(ctx context.Context, publicID string) (T, error) {
+	return t.find(func(row *T) bool { return *t.publicID(row) == publicID })
+}
+
+// exists reports whether a row with the ID is stored.
+func (t *table[T]) exists(id int64) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	_, ok := t.rows[id]
+	return ok
+}
+
+// get returns a copy of the row with the ID, without associations, or nil.
+func (t *table[T]) get(id int64) *T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	row, ok := t.rows[id]
+	if !ok {
+		return nil
+	}
+	return &row
+}
+
+// deleteWhere removes every row matching the predicate.
+func (t *table[T]) deleteWhere(match func(row *T) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, row := range t.rows {
+		if match(&row) {
+			delete(t.rows, id)
+		}
+	}
+}
+
+// any reports whether a stored row matches the predicate.
+func (t *table[T]) any(match func(row *T) bool) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, row := range t.rows {
+		if match(&row) {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the first row, in ID order, matching the predicate.
+func (t *table[T]) find(match func(row *T) bool) (T, error) {
+	rows := t.all()
+	for i := range rows {
+		if match(&rows[i]) {
+			return rows[i], nil
+		}
+	}
+
+	var zero T
+	return zero, repository.ErrNotFound
+}
+
+func (t *table[T]) Create(ctx context.Context, row *T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conflictsWith(row, 0) {
+		return repository.ErrDuplicate
+	}
+	if t.parents != nil && !t.parents(row) {
+		return repository.ErrReferenced
+	}
+
+	if t.publicID != nil {
+		if publicID := t.publicID(row); *publicID == "" {
+			*publicID = core.NewPublicID()
+		}
+	}
+
+	id, createdAt, updatedAt := t.fields(row)
+	t.lastID++
+	*id = t.lastID
+	*createdAt = time.Now()
+	*updatedAt = *createdAt
+	t.rows[*id] = *row
+	return nil
+}
+
+func (t *table[T]) Update(ctx context.Context, row *T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id, _, updatedAt := t.fields(row)
+	if _, ok := t.rows[*id]; !ok {
+		return repository.ErrNotFound
+	}
+	if t.conflictsWith(row, *id) {
+		return repository.ErrDuplicate
+	}
+	if t.parents != nil && !t.parents(row) {
+		return repository.ErrReferenced
+	}
+
+	*updatedAt = time.Now()
+	t.rows[*id] = *row
+	return nil
+}
+
+func (t *table[T]) Delete(ctx context.Context, row *T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id, _, _ := t.fields(row)
+	delete(t.rows, *id)
+	return nil
+}
+
+// conflictsWith checks row against every stored row except the one with the given ID.
+func (t *table[T]) conflictsWith(row *T, skipID int64) bool {
+	if t.conflicts == nil {
+		return false
+	}
+	for id, existing := range t.rows {
+		if id != skipID && t.conflicts(&existing, row) {
+			return true
+		}
+	}
+	return false
+}
+
+type UserRepository struct {
+	*table[core.User]
+	photos      *table[core.Photo]
+	comments    *table[core.Comment]
+	socialMedia *table[core.SocialMedia]
+}
+
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (core.User, error) {
+	return r.find(func(u *core.User) bool { return u.Email == email })
+}
+
+func (r *UserRepository) HasDependents(ctx context.Context, id int64) (bool, error) {
+	return r.photos.any(func(p *core.Photo) bool { return p.UserID == id }) ||
+		r.comments.any(func(c *core.Comment) bool { return c.UserID == id }) ||
+		r.socialMedia.any(func(s *core.SocialMedia) bool { return s.UserID == id }), nil
+}
+
+func (r *UserRepository) DeleteCascade(ctx context.Context, user *core.User) error {
+	photos, _ := r.photos.FindAll(ctx)
+	owned := make(map[int64]bool)
+	for _, photo := range photos {
+		if photo.UserID == user.ID {
+			owned[photo.ID] = true
+		}
+	}
+
+	r.comments.deleteWhere(func(c *core.Comment) bool { return c.UserID == user.ID || owned[c.PhotoID] })
+	r.photos.deleteWhere(func(p *core.Photo) bool { return p.UserID == user.ID })
+	r.socialMedia.deleteWhere(func(s *core.SocialMedia) bool { return s.UserID == user.ID })
+	return r.Delete(ctx, user)
+}
+
+type PhotoRepository struct {
+	*table[core.Photo]
+	comments *table[core.Comment]
+}
+
+func (r *PhotoRepository) HasDependents(ctx context.Context, id int64) (bool, error) {
+	return r.comments.any(func(c *core.Comment) bool { return c.PhotoID == id }), nil
+}
+
+func (r *PhotoRepository) DeleteCascade(ctx context.Context, photo *core.Photo) error {
+	r.comments.deleteWhere(func(c *core.Comment) bool { return c.PhotoID == photo.ID })
+	return r.Delete(ctx, photo)
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"finalproject/repository"
+	"finalproject/repository/memory"
+	"finalproject/repository/repositorytest"
+)
+
+func TestRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repositories {
+		return memory.NewRepositories()
+	})
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+type PasswordResetTokenRepository struct {
+	*table[core.PasswordResetToken]
+}
+
+func (r *PasswordResetTokenRepository) FindByHash(ctx context.Context, hash string) (core.PasswordResetToken, error) {
+	return r.find(func(t *core.PasswordResetToken) bool { return t.TokenHash == hash })
+}
+
+func (r *PasswordResetTokenRepository) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.rows[id]
+	if !ok || token.UsedAt != nil {
+		return repository.ErrNotFound
+	}
+	token.UsedAt = &at
+	token.UpdatedAt = at
+	r.rows[id] = token
+	return nil
+}
+
+func (r *PasswordResetTokenRepository) InvalidateForUser(ctx context.Context, userID int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.rows {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &at
+			token.UpdatedAt = at
+			r.rows[id] = token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+type RecoveryCodeRepository struct {
+	*table[core.RecoveryCode]
+}
+
+func (r *RecoveryCodeRepository) Replace(ctx context.Context, userID int64, codes []core.RecoveryCode) error {
+	if err := r.DeleteByUser(ctx, userID); err != nil {
+		return err
+	}
+	for i := range codes {
+		codes[i].UserID = userID
+		if err := r.Create(ctx, &codes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RecoveryCodeRepository) FindUnusedByUser(ctx context.Context, userID int64) ([]core.RecoveryCode, error) {
+	all, _ := r.FindAll(ctx)
+
+	var codes []core.RecoveryCode
+	for _, code := range all {
+		if code.UserID == userID && code.UsedAt == nil {
+			codes = append(codes, code)
+		}
+	}
+	return codes, nil
+}
+
+func (r *RecoveryCodeRepository) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.rows[id]
+	if !ok || code.UsedAt != nil {
+		return repository.ErrNotFound
+	}
+	code.UsedAt = &at
+	code.UpdatedAt = at
+	r.rows[id] = code
+	return nil
+}
+
+func (r *RecoveryCodeRepository) DeleteByUser(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, code := range r.rows {
+		if code.UserID == userID {
+			delete(r.rows, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+type RefreshTokenRepository struct {
+	*table[core.RefreshToken]
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, hash string) (core.RefreshToken, error) {
+	return r.find(func(t *core.RefreshToken) bool { return t.TokenHash == hash })
+}
+
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.rows[id]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return repository.ErrNotFound
+	}
+	token.UsedAt = &at
+	token.UpdatedAt = at
+	r.rows[id] = token
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.rows {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			token.UpdatedAt = at
+			r.rows[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) FindActiveByUser(ctx context.Context, userID int64, now time.Time) ([]core.RefreshToken, error) {
+	all, _ := r.FindAll(ctx)
+
+	var tokens []core.RefreshToken
+	for _, token := range all {
+		if token.UserID == userID && token.Active(now) {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.SliceStable(tokens, func(i, j int) bool { return tokens[i].CreatedAt.After(tokens[j].CreatedAt) })
+	return tokens, nil
+}
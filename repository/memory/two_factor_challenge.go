@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+type TwoFactorChallengeRepository struct {
+	*table[core.TwoFactorChallenge]
+}
+
+func (r *TwoFactorChallengeRepository) FindByHash(ctx context.Context, hash string) (core.TwoFactorChallenge, error) {
+	return r.find(func(c *core.TwoFactorChallenge) bool { return c.TokenHash == hash })
+}
+
+func (r *TwoFactorChallengeRepository) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	challenge, ok := r.rows[id]
+	if !ok || challenge.UsedAt != nil {
+		return repository.ErrNotFound
+	}
+	challenge.UsedAt = &at
+	challenge.UpdatedAt = at
+	r.rows[id] = challenge
+	return nil
+}
+
+func (r *TwoFactorChallengeRepository) RecordFailure(ctx context.Context, id int64, maxFailures int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	challenge, ok := r.rows[id]
+	if !ok {
+		return nil
+	}
+	challenge.Failures++
+	if challenge.Failures >= maxFailures && challenge.UsedAt == nil {
+		challenge.UsedAt = &at
+	}
+	challenge.UpdatedAt = at
+	r.rows[id] = challenge
+	return nil
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"finalproject/core"
+)
+
+type LoginAttemptRepository struct {
+	table[core.LoginAttempt]
+}
+
+func (r *LoginAttemptRepository) FindByKey(ctx context.Context, key string) (core.LoginAttempt, error) {
+	var attempt core.LoginAttempt
+	err := r.db.WithContext(ctx).Where("key = ?", key).First(&attempt).Error
+	return attempt, translate(err)
+}
+
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (core.LoginAttempt, error) {
+	// A single upsert keeps concurrent failures from losing increments
+	var attempt core.LoginAttempt
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO login_attempts (key, failures, last_failure_at, created_at, updated_at)
+		VALUES (@key, 1, @at, @at, @at)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < @since THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = @at,
+			updated_at = @at
+		RETURNING *`,
+		map[string]interface{}{"key": key, "at": at, "since": at.Add(-window)},
+	).Scan(&attempt).Error
+	return attempt, translate(err)
+}
+
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	err := r.db.WithContext(ctx).Model(&core.LoginAttempt{}).Where("key = ?", key).Update("locked_until", until).Error
+	return translate(err)
+}
+
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	return translate(r.db.WithContext(ctx).Where("key = ?", key).Delete(&core.LoginAttempt{}).Error)
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+type PasswordResetTokenRepository struct {
+	table[core.PasswordResetToken]
+}
+
+func (r *PasswordResetTokenRepository) FindByHash(ctx context.Context, hash string) (core.PasswordResetToken, error) {
+	var token core.PasswordResetToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error
+	return token, translate(err)
+}
+
+func (r *PasswordResetTokenRepository) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&core.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PasswordResetTokenRepository) InvalidateForUser(ctx context.Context, userID int64, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(&core.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+	return translate(err)
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"finalproject/core"
+	"finalproject/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NewRepositories returns gorm-backed repositories sharing one connection pool.
+func NewRepositories(db *gorm.DB) repository.Repositories {
+	return repository.Repositories{
+		Users:       &UserRepository{table[core.User]{db: db}},
+		Photos:      &PhotoRepository{table[core.Photo]{db: db, preload: []string{"User"}}},
+		Comments:    &table[core.Comment]{db: db, preload: []string{"User", "Photo"}},
+		SocialMedia: &table[core.SocialMedia]{db: db, preload: []string{"User"}},
+
+		RefreshTokens:       &RefreshTokenRepository{table[core.RefreshToken]{db: db}},
+		PasswordResetTokens: &PasswordResetTokenRepository{table[core.PasswordResetToken]{db: db}},
+		TwoFactorChallenges: &TwoFactorChallengeRepository{table[core.TwoFactorChallenge]{db: db}},
+		RecoveryCodes:       &RecoveryCodeRepository{table[core.RecoveryCode]{db: db}},
+		LoginAttempts:       &LoginAttemptRepository{table[core.LoginAttempt]{db: db}},
+	}
+}
+
+// table implements the CRUD operations shared by every model.
+type table[T any] struct {
+	db *gorm.DB
+	// preload names the associations loaded by FindAll and FindByID.
+	preload []string
+}
+
+// query starts a read with the associations of the table preloaded.
+func (t *table[T]) query(ctx context.Context) *gorm.DB {
+	query := t.db.WithContext(ctx)
+	for _, association := range t.preload {
+		query = query.Preload(association)
+	}
+	return query
+}
+
+func (t *table[T]) FindAll(ctx context.Context) ([]T, error) {
+	var rows []T
+	err := t.query(ctx).Order("id").Find(&rows).Error
+	return rows, translate(err)
+}
+
+func (t *table[T]) FindByID(ctx context.Context, id int64) (T, error) {
+	var row T
+	err := t.query(ctx).Where("id = ?", id).First(&row).Error
+	return row, translate(err)
+}
+
+// FindByPublicID is only valid for models embedding core.Model.
+func (t *table[T]) FindByPublicID(ctx context.Context, publicID string) (T, error) {
+	var row T
+	err := t.query(ctx).Where("public_id = ?", publicID).First(&row).Error
+	return row, translate(err)
+}
+
+// Create and Update never write associations; related rows are saved through their own repository.
+func (t *table[T]) Create(ctx context.Context, row *T) error {
+	return translate(t.db.WithContext(ctx).Omit(clause.Associations).Create(row).Error)
+}
+
+func (t *table[T]) Update(ctx context.Context, row *T) error {
+	return translate(t.db.WithContext(ctx).Omit(clause.Associations).Save(row).Error)
+}
+
+func (t *table[T]) Delete(ctx context.Context, row *T) error {
+	return translate(t.db.WithContext(ctx).Delete(row).Error)
+}
+
+type UserRepository struct {
+	table[core.User]
+}
+
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (core.User, error) {
+	var user core.User
+	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	return user, translate(err)
+}
+
+func (r *UserRepository) HasDependents(ctx context.Context, id int64) (bool, error) {
+	for _, model := range []any{&core.Photo{}, &core.Comment{}, &core.SocialMedia{}} {
+		var count int64
+		if err := r.db.WithContext(ctx).Model(model).Where("user_id = ?", id).Limit(1).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *UserRepository) DeleteCascade(ctx context.Context, user *core.User) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		photoIDs := tx.Model(&core.Photo{}).Select("id").Where("user_id = ?", user.ID)
+		if err := tx.Where("photo_id IN (?) OR user_id = ?", photoIDs, user.ID).Delete(&core.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&core.Photo{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&core.SocialMedia{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(user).Error
+	}))
+}
+
+type PhotoRepository struct {
+	table[core.Photo]
+}
+
+func (r *PhotoRepository) HasDependents(ctx context.Context, id int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&core.Comment{}).Where("photo_id = ?", id).Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *PhotoRepository) DeleteCascade(ctx context.Context, photo *core.Photo) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("photo_id = ?", photo.ID).Delete(&core.Comment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(photo).Error
+	}))
+}
+
+// translate maps gorm errors onto the repository sentinel errors.
+func translate(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return repository.ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return repository.ErrDuplicate
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return repository.ErrReferenced
+	}
+	return err
+}
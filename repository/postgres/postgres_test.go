@@ -0,0 +1,45 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"finalproject/config"
+	"finalproject/database"
+	"finalproject/repository"
+	"finalproject/repository/postgres"
+	"finalproject/repository/repositorytest"
+
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// TestRepositories needs a disposable database, e.g.
+// TEST_DATABASE_URL=postgres://postgres@localhost:5432/mygram_test?sslmode=disable. Every table is truncated.
+func TestRepositories(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	conn, err := database.NewPostgres(config.DatabaseConfig{URL: config.Secret(url)}, gormlogger.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := database.NewMigrator(conn.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	repositorytest.Run(t, func(t *testing.T) repository.Repositories {
+		truncate := "TRUNCATE " + strings.Join(database.Tables, ", ") + " RESTART IDENTITY CASCADE"
+		if err := conn.DB.Exec(truncate).Error; err != nil {
+			t.Fatal(err)
+		}
+		return postgres.NewRepositories(conn.DB)
+	})
+}
@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository struct {
+	table[core.RecoveryCode]
+}
+
+func (r *RecoveryCodeRepository) Replace(ctx context.Context, userID int64, codes []core.RecoveryCode) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&core.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		for i := range codes {
+			codes[i].UserID = userID
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+	return translate(err)
+}
+
+func (r *RecoveryCodeRepository) FindUnusedByUser(ctx context.Context, userID int64) ([]core.RecoveryCode, error) {
+	var codes []core.RecoveryCode
+	err := r.db.WithContext(ctx).Where("user_id = ? AND used_at IS NULL", userID).Order("id").Find(&codes).Error
+	return codes, translate(err)
+}
+
+func (r *RecoveryCodeRepository) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&core.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RecoveryCodeRepository) DeleteByUser(ctx context.Context, userID int64) error {
+	return translate(r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&core.RecoveryCode{}).Error)
+}
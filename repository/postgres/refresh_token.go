@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+type RefreshTokenRepository struct {
+	table[core.RefreshToken]
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, hash string) (core.RefreshToken, error) {
+	var token core.RefreshToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error
+	return token, translate(err)
+}
+
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&core.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(&core.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+	return translate(err)
+}
+
+func (r *RefreshTokenRepository) FindActiveByUser(ctx context.Context, userID int64, now time.Time) ([]core.RefreshToken, error) {
+	var tokens []core.RefreshToken
+	err := r.db.WithContext(ctx).Where("user_id = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, translate(err)
+}
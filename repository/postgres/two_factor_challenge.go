@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorChallengeRepository struct {
+	table[core.TwoFactorChallenge]
+}
+
+func (r *TwoFactorChallengeRepository) FindByHash(ctx context.Context, hash string) (core.TwoFactorChallenge, error) {
+	var challenge core.TwoFactorChallenge
+	err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&challenge).Error
+	return challenge, translate(err)
+}
+
+func (r *TwoFactorChallengeRepository) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&core.TwoFactorChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *TwoFactorChallengeRepository) RecordFailure(ctx context.Context, id int64, maxFailures int, at time.Time) error {
+	// A single update keeps concurrent guesses from losing increments
+	err := r.db.WithContext(ctx).Model(&core.TwoFactorChallenge{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"failures":   gorm.Expr("failures + 1"),
+			"used_at":    gorm.Expr("CASE WHEN failures + 1 >= ? THEN COALESCE(used_at, ?) ELSE used_at END", maxFailures, at),
+			"updated_at": at,
+		}).Error
+	return translate(err)
+}
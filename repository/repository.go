@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"finalproject/core"
+)
+
+var (
+	// ErrNotFound is returned when no record matches the lookup.
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when a write violates a unique constraint.
+	ErrDuplicate = errors.New("duplicate record")
+	// ErrReferenced is returned when a write violates a foreign key, e.g. a comment on a missing photo.
+	ErrReferenced = errors.New("foreign key violation")
+)
+
+// Users, photos, comments and social media are looked up by their internal ID within the application
+// and by their public ID when the ID comes from a client.
+//
+// Reads of photos, comments and social media populate their User (and, for comments, Photo)
+// associations so responses can embed them.
+
+type UserRepository interface {
+	FindAll(ctx context.Context) ([]core.User, error)
+	FindByID(ctx context.Context, id int64) (core.User, error)
+	FindByPublicID(ctx context.Context, publicID string) (core.User, error)
+	FindByEmail(ctx context.Context, email string) (core.User, error)
+	Create(ctx context.Context, user *core.User) error
+	Update(ctx context.Context, user *core.User) error
+	Delete(ctx context.Context, user *core.User) error
+	// HasDependents reports whether the user owns photos, comments or social media.
+	HasDependents(ctx context.Context, id int64) (bool, error)
+	// DeleteCascade soft-deletes the user with their photos, the comments on those photos,
+	// their own comments and their social media, all or nothing.
+	DeleteCascade(ctx context.Context, user *core.User) error
+}
+
+type PhotoRepository interface {
+	FindAll(ctx context.Context) ([]core.Photo, error)
+	FindByID(ctx context.Context, id int64) (core.Photo, error)
+	FindByPublicID(ctx context.Context, publicID string) (core.Photo, error)
+	Create(ctx context.Context, photo *core.Photo) error
+	Update(ctx context.Context, photo *core.Photo) error
+	Delete(ctx context.Context, photo *core.Photo) error
+	// HasDependents reports whether the photo has comments.
+	HasDependents(ctx context.Context, id int64) (bool, error)
+	// DeleteCascade soft-deletes the photo and its comments, all or nothing.
+	DeleteCascade(ctx context.Context, photo *core.Photo) error
+}
+
+type CommentRepository interface {
+	FindAll(ctx context.Context) ([]core.Comment, error)
+	FindByID(ctx context.Context, id int64) (core.Comment, error)
+	FindByPublicID(ctx context.Context, publicID string) (core.Comment, error)
+	Create(ctx context.Context, comment *core.Comment) error
+	Update(ctx context.Context, comment *core.Comment) error
+	Delete(ctx context.Context, comment *core.Comment) error
+}
+
+type SocialMediaRepository interface {
+	FindAll(ctx context.Context) ([]core.SocialMedia, error)
+	FindByID(ctx context.Context, id int64) (core.SocialMedia, error)
+	FindByPublicID(ctx context.Context, publicID string) (core.SocialMedia, error)
+	Create(ctx context.Context, socialMedia *core.SocialMedia) error
+	Update(ctx context.Context, socialMedia *core.SocialMedia) error
+	Delete(ctx context.Context, socialMedia *core.SocialMedia) error
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *core.RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (core.RefreshToken, error)
+	// MarkUsed flags an active token as rotated. It returns ErrNotFound when the token was
+	// already used or revoked, so two concurrent refreshes cannot both succeed.
+	MarkUsed(ctx context.Context, id int64, at time.Time) error
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+	// FindActiveByUser returns the current, unexpired head token of each of the user's families.
+	FindActiveByUser(ctx context.Context, userID int64, now time.Time) ([]core.RefreshToken, error)
+}
+
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *core.PasswordResetToken) error
+	FindByHash(ctx context.Context, hash string) (core.PasswordResetToken, error)
+	// MarkUsed redeems an unused token. It returns ErrNotFound when the token was already used.
+	MarkUsed(ctx context.Context, id int64, at time.Time) error
+	// InvalidateForUser marks every unused token of the user as used, so only the newest link works.
+	InvalidateForUser(ctx context.Context, userID int64, at time.Time) error
+}
+
+type TwoFactorChallengeRepository interface {
+	Create(ctx context.Context, challenge *core.TwoFactorChallenge) error
+	FindByHash(ctx context.Context, hash string) (core.TwoFactorChallenge, error)
+	// MarkUsed redeems an unused challenge. It returns ErrNotFound when the challenge was already used,
+	// so two concurrent verifications cannot both succeed.
+	MarkUsed(ctx context.Context, id int64, at time.Time) error
+	// RecordFailure atomically counts a wrong code, burning the challenge once maxFailures is reached.
+	RecordFailure(ctx context.Context, id int64, maxFailures int, at time.Time) error
+}
+
+type RecoveryCodeRepository interface {
+	// Replace deletes every recovery code of the user and stores the new ones.
+	Replace(ctx context.Context, userID int64, codes []core.RecoveryCode) error
+	FindUnusedByUser(ctx context.Context, userID int64) ([]core.RecoveryCode, error)
+	// MarkUsed redeems an unused code. It returns ErrNotFound when the code was already used.
+	MarkUsed(ctx context.Context, id int64, at time.Time) error
+	DeleteByUser(ctx context.Context, userID int64) error
+}
+
+type LoginAttemptRepository interface {
+	FindByKey(ctx context.Context, key string) (core.LoginAttempt, error)
+	// RecordFailure atomically increments the failure counter of key, restarting from one when the
+	// previous failure is older than window, and returns the updated row.
+	RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (core.LoginAttempt, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// Repositories groups one implementation of every repository so storage can be swapped as a unit.
+type Repositories struct {
+	Users       UserRepository
+	Photos      PhotoRepository
+	Comments    CommentRepository
+	SocialMedia SocialMediaRepository
+
+	RefreshTokens       RefreshTokenRepository
+	PasswordResetTokens PasswordResetTokenRepository
+	TwoFactorChallenges TwoFactorChallengeRepository
+	RecoveryCodes       RecoveryCodeRepository
+	LoginAttempts       LoginAttemptRepository
+}
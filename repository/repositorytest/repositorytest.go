@@ -0,0 +1,330 @@
+// Package repositorytest checks that an implementation of repository.Repositories behaves like the
+// gorm repositories: unique constraints, foreign keys, cascades and the atomic counters.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+// Run runs the contract against the repositories returned by open, which is called once per test
+// and must return repositories over empty tables.
+func Run(t *testing.T, open func(t *testing.T) repository.Repositories) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repos repository.Repositories)
+	}{
+		{"UserCreate", testUserCreate},
+		{"UserConflicts", testUserConflicts},
+		{"DeletedUserReleasesEmail", testDeletedUserReleasesEmail},
+		{"ForeignKeys", testForeignKeys},
+		{"UserDeleteCascade", testUserDeleteCascade},
+		{"PhotoDeleteCascade", testPhotoDeleteCascade},
+		{"LoginAttemptWindow", testLoginAttemptWindow},
+		{"LoginAttemptLock", testLoginAttemptLock},
+		{"RefreshTokenSingleUse", testRefreshTokenSingleUse},
+		{"TwoFactorChallengeFailures", testTwoFactorChallengeFailures},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, open(t))
+		})
+	}
+}
+
+func createUser(t *testing.T, repos repository.Repositories, name string) core.User {
+	t.Helper()
+	user := core.User{Username: name, Email: name + "@example.com", Password: "hash", Age: 20, Role: core.RoleUser}
+	if err := repos.Users.Create(context.Background(), &user); err != nil {
+		t.Fatalf("create user %s: %v", name, err)
+	}
+	return user
+}
+
+func createPhoto(t *testing.T, repos repository.Repositories, owner core.User) core.Photo {
+	t.Helper()
+	photo := core.Photo{Title: "photo", PhotoURL: "https://example.com/photo.jpg", UserID: owner.ID}
+	if err := repos.Photos.Create(context.Background(), &photo); err != nil {
+		t.Fatalf("create photo: %v", err)
+	}
+	return photo
+}
+
+func createComment(t *testing.T, repos repository.Repositories, author core.User, photo core.Photo) core.Comment {
+	t.Helper()
+	comment := core.Comment{Message: "comment", UserID: author.ID, PhotoID: photo.ID}
+	if err := repos.Comments.Create(context.Background(), &comment); err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	return comment
+}
+
+func wantErr(t *testing.T, what string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("%s: got %v, want %v", what, err, want)
+	}
+}
+
+func testUserCreate(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	user := createUser(t, repos, "jane")
+
+	if user.ID == 0 || !core.ValidPublicID(user.PublicID) || user.CreatedAt.IsZero() {
+		t.Fatalf("create did not assign the ID, public ID and timestamps: %+v", user)
+	}
+	for what, find := range map[string]func() (core.User, error){
+		"FindByID":       func() (core.User, error) { return repos.Users.FindByID(ctx, user.ID) },
+		"FindByPublicID": func() (core.User, error) { return repos.Users.FindByPublicID(ctx, user.PublicID) },
+		"FindByEmail":    func() (core.User, error) { return repos.Users.FindByEmail(ctx, user.Email) },
+	} {
+		found, err := find()
+		if err != nil || found.ID != user.ID {
+			t.Errorf("%s: got user %d, %v", what, found.ID, err)
+		}
+	}
+
+	_, err := repos.Users.FindByEmail(ctx, "nobody@example.com")
+	wantErr(t, "FindByEmail of an unknown email", err, repository.ErrNotFound)
+	_, err = repos.Users.FindByPublicID(ctx, core.NewPublicID())
+	wantErr(t, "FindByPublicID of an unknown ID", err, repository.ErrNotFound)
+}
+
+func testUserConflicts(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	jane := createUser(t, repos, "jane")
+	john := createUser(t, repos, "john")
+
+	sameEmail := core.User{Username: "other", Email: jane.Email, Password: "hash", Age: 20, Role: core.RoleUser}
+	wantErr(t, "create with a taken email", repos.Users.Create(ctx, &sameEmail), repository.ErrDuplicate)
+	sameUsername := core.User{Username: jane.Username, Email: "other@example.com", Password: "hash", Age: 20, Role: core.RoleUser}
+	wantErr(t, "create with a taken username", repos.Users.Create(ctx, &sameUsername), repository.ErrDuplicate)
+
+	john.Email = jane.Email
+	wantErr(t, "update to a taken email", repos.Users.Update(ctx, &john), repository.ErrDuplicate)
+
+	// A row never conflicts with itself
+	jane.Age = 30
+	if err := repos.Users.Update(ctx, &jane); err != nil {
+		t.Errorf("update without changing unique columns: %v", err)
+	}
+}
+
+// A deleted account, soft-deleted or not, no longer holds its username and email.
+func testDeletedUserReleasesEmail(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	jane := createUser(t, repos, "jane")
+	if err := repos.Users.Delete(ctx, &jane); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+
+	again := createUser(t, repos, "jane")
+	found, err := repos.Users.FindByEmail(ctx, jane.Email)
+	if err != nil {
+		t.Fatalf("FindByEmail after re-registering: %v", err)
+	}
+	if found.ID != again.ID {
+		t.Errorf("FindByEmail returned user %d, want the live account %d", found.ID, again.ID)
+	}
+}
+
+func testForeignKeys(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	jane := createUser(t, repos, "jane")
+	photo := createPhoto(t, repos, jane)
+
+	orphan := core.Photo{Title: "photo", PhotoURL: "https://example.com/photo.jpg", UserID: jane.ID + 1000}
+	wantErr(t, "photo of a missing user", repos.Photos.Create(ctx, &orphan), repository.ErrReferenced)
+	comment := core.Comment{Message: "comment", UserID: jane.ID, PhotoID: photo.ID + 1000}
+	wantErr(t, "comment on a missing photo", repos.Comments.Create(ctx, &comment), repository.ErrReferenced)
+
+	// Reads populate the associations
+	found, err := repos.Photos.FindByID(ctx, photo.ID)
+	if err != nil || found.User == nil || found.User.ID != jane.ID {
+		t.Errorf("photo owner was not loaded: %+v, %v", found.User, err)
+	}
+	createComment(t, repos, jane, photo)
+	comments, err := repos.Comments.FindAll(ctx)
+	if err != nil || len(comments) != 1 || comments[0].User == nil || comments[0].Photo == nil {
+		t.Errorf("comment associations were not loaded: %+v, %v", comments, err)
+	}
+}
+
+func testUserDeleteCascade(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	jane := createUser(t, repos, "jane")
+	john := createUser(t, repos, "john")
+
+	janePhoto := createPhoto(t, repos, jane)
+	johnPhoto := createPhoto(t, repos, john)
+	johnOnJane := createComment(t, repos, john, janePhoto)
+	janeOnJohn := createComment(t, repos, jane, johnPhoto)
+	johnOnJohn := createComment(t, repos, john, johnPhoto)
+	janeMedia := core.SocialMedia{Name: "site", SocialMediaURL: "https://example.com/jane", UserID: jane.ID}
+	if err := repos.SocialMedia.Create(ctx, &janeMedia); err != nil {
+		t.Fatal(err)
+	}
+
+	if has, err := repos.Users.HasDependents(ctx, jane.ID); err != nil || !has {
+		t.Fatalf("HasDependents before the cascade: %v, %v", has, err)
+	}
+	if err := repos.Users.DeleteCascade(ctx, &jane); err != nil {
+		t.Fatal(err)
+	}
+
+	// Jane, her photo, every comment on it, her comments elsewhere and her social media are gone
+	_, err := repos.Users.FindByID(ctx, jane.ID)
+	wantErr(t, "deleted user", err, repository.ErrNotFound)
+	_, err = repos.Photos.FindByID(ctx, janePhoto.ID)
+	wantErr(t, "photo of the deleted user", err, repository.ErrNotFound)
+	_, err = repos.Comments.FindByID(ctx, johnOnJane.ID)
+	wantErr(t, "comment on a photo of the deleted user", err, repository.ErrNotFound)
+	_, err = repos.Comments.FindByID(ctx, janeOnJohn.ID)
+	wantErr(t, "comment by the deleted user", err, repository.ErrNotFound)
+	_, err = repos.SocialMedia.FindByID(ctx, janeMedia.ID)
+	wantErr(t, "social media of the deleted user", err, repository.ErrNotFound)
+
+	// John's own content stays
+	if _, err := repos.Photos.FindByID(ctx, johnPhoto.ID); err != nil {
+		t.Errorf("unrelated photo: %v", err)
+	}
+	if _, err := repos.Comments.FindByID(ctx, johnOnJohn.ID); err != nil {
+		t.Errorf("unrelated comment: %v", err)
+	}
+	if has, err := repos.Users.HasDependents(ctx, jane.ID); err != nil || has {
+		t.Errorf("HasDependents after the cascade: %v, %v", has, err)
+	}
+}
+
+func testPhotoDeleteCascade(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	jane := createUser(t, repos, "jane")
+	photo := createPhoto(t, repos, jane)
+	other := createPhoto(t, repos, jane)
+	onPhoto := createComment(t, repos, jane, photo)
+	onOther := createComment(t, repos, jane, other)
+
+	if has, err := repos.Photos.HasDependents(ctx, photo.ID); err != nil || !has {
+		t.Fatalf("HasDependents before the cascade: %v, %v", has, err)
+	}
+	if err := repos.Photos.DeleteCascade(ctx, &photo); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := repos.Photos.FindByID(ctx, photo.ID)
+	wantErr(t, "deleted photo", err, repository.ErrNotFound)
+	_, err = repos.Comments.FindByID(ctx, onPhoto.ID)
+	wantErr(t, "comment on the deleted photo", err, repository.ErrNotFound)
+	if _, err := repos.Comments.FindByID(ctx, onOther.ID); err != nil {
+		t.Errorf("comment on another photo: %v", err)
+	}
+}
+
+func testLoginAttemptWindow(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	window := 15 * time.Minute
+	// Whole seconds, so the comparisons survive the database's timestamp precision
+	start := time.Now().Truncate(time.Second)
+
+	steps := []struct {
+		at   time.Time
+		want int
+	}{
+		{start, 1},
+		{start.Add(time.Minute), 2},
+		// A failure exactly one window after the last still counts
+		{start.Add(time.Minute + window), 3},
+		// A failure after a quiet window starts over
+		{start.Add(2*time.Minute + 2*window), 1},
+		{start.Add(3*time.Minute + 2*window), 2},
+	}
+	for i, step := range steps {
+		attempt, err := repos.LoginAttempts.RecordFailure(ctx, "email:jane@example.com", step.at, window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attempt.Failures != step.want || !attempt.LastFailureAt.Equal(step.at) {
+			t.Errorf("failure %d: got %d failures at %s, want %d at %s", i+1, attempt.Failures, attempt.LastFailureAt, step.want, step.at)
+		}
+	}
+
+	// Keys are counted apart
+	attempt, err := repos.LoginAttempts.RecordFailure(ctx, "ip:192.0.2.1", start, window)
+	if err != nil || attempt.Failures != 1 {
+		t.Errorf("other key: got %d failures, %v", attempt.Failures, err)
+	}
+	found, err := repos.LoginAttempts.FindByKey(ctx, "email:jane@example.com")
+	if err != nil || found.Failures != 2 {
+		t.Errorf("FindByKey: got %d failures, %v", found.Failures, err)
+	}
+}
+
+func testLoginAttemptLock(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	if _, err := repos.LoginAttempts.RecordFailure(ctx, "ip:192.0.2.1", now, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.LoginAttempts.Lock(ctx, "ip:192.0.2.1", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	attempt, err := repos.LoginAttempts.FindByKey(ctx, "ip:192.0.2.1")
+	if err != nil || !attempt.Locked(now) || attempt.Locked(now.Add(time.Minute)) {
+		t.Errorf("lock: got %+v, %v", attempt, err)
+	}
+
+	if err := repos.LoginAttempts.Reset(ctx, "ip:192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repos.LoginAttempts.FindByKey(ctx, "ip:192.0.2.1")
+	wantErr(t, "reset key", err, repository.ErrNotFound)
+	if err := repos.LoginAttempts.Reset(ctx, "ip:192.0.2.1"); err != nil {
+		t.Errorf("reset of an unknown key: %v", err)
+	}
+}
+
+func testRefreshTokenSingleUse(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	jane := createUser(t, repos, "jane")
+	now := time.Now()
+
+	token := core.RefreshToken{UserID: jane.ID, FamilyID: "family", TokenHash: "hash", ExpiresAt: now.Add(time.Hour)}
+	if err := repos.RefreshTokens.Create(ctx, &token); err != nil {
+		t.Fatal(err)
+	}
+	duplicate := core.RefreshToken{UserID: jane.ID, FamilyID: "family", TokenHash: "hash", ExpiresAt: now.Add(time.Hour)}
+	wantErr(t, "token with a taken hash", repos.RefreshTokens.Create(ctx, &duplicate), repository.ErrDuplicate)
+
+	if err := repos.RefreshTokens.MarkUsed(ctx, token.ID, now); err != nil {
+		t.Fatal(err)
+	}
+	wantErr(t, "second MarkUsed", repos.RefreshTokens.MarkUsed(ctx, token.ID, now), repository.ErrNotFound)
+}
+
+func testTwoFactorChallengeFailures(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	jane := createUser(t, repos, "jane")
+	now := time.Now()
+
+	challenge := core.TwoFactorChallenge{UserID: jane.ID, TokenHash: "hash", ExpiresAt: now.Add(time.Minute)}
+	if err := repos.TwoFactorChallenges.Create(ctx, &challenge); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		if err := repos.TwoFactorChallenges.RecordFailure(ctx, challenge.ID, 2, now); err != nil {
+			t.Fatal(err)
+		}
+		found, err := repos.TwoFactorChallenges.FindByHash(ctx, "hash")
+		if err != nil || found.Failures != i || found.Active(now) != (i < 2) {
+			t.Errorf("failure %d: got %d failures, active %v, %v", i, found.Failures, found.Active(now), err)
+		}
+	}
+	wantErr(t, "MarkUsed of a burnt challenge", repos.TwoFactorChallenges.MarkUsed(ctx, challenge.ID, now), repository.ErrNotFound)
+}
@@ -0,0 +1,204 @@
+package traced
+
+import (
+	"context"
+	"time"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+// crudRepository is the method set shared by the user, photo, comment and social media repositories.
+type crudRepository[T any] interface {
+	FindAll(ctx context.Context) ([]T, error)
+	FindByID(ctx context.Context, id int64) (T, error)
+	FindByPublicID(ctx context.Context, publicID string) (T, error)
+	Create(ctx context.Context, entity *T) error
+	Update(ctx context.Context, entity *T) error
+	Delete(ctx context.Context, entity *T) error
+}
+
+type crud[T any] struct {
+	next crudRepository[T]
+	name string
+}
+
+func (r crud[T]) FindAll(ctx context.Context) ([]T, error) {
+	return call(ctx, r.name+".FindAll", r.next.FindAll)
+}
+
+func (r crud[T]) FindByID(ctx context.Context, id int64) (T, error) {
+	return call(ctx, r.name+".FindByID", func(ctx context.Context) (T, error) { return r.next.FindByID(ctx, id) })
+}
+
+func (r crud[T]) FindByPublicID(ctx context.Context, publicID string) (T, error) {
+	return call(ctx, r.name+".FindByPublicID", func(ctx context.Context) (T, error) { return r.next.FindByPublicID(ctx, publicID) })
+}
+
+func (r crud[T]) Create(ctx context.Context, entity *T) error {
+	return exec(ctx, r.name+".Create", func(ctx context.Context) error { return r.next.Create(ctx, entity) })
+}
+
+func (r crud[T]) Update(ctx context.Context, entity *T) error {
+	return exec(ctx, r.name+".Update", func(ctx context.Context) error { return r.next.Update(ctx, entity) })
+}
+
+func (r crud[T]) Delete(ctx context.Context, entity *T) error {
+	return exec(ctx, r.name+".Delete", func(ctx context.Context) error { return r.next.Delete(ctx, entity) })
+}
+
+type users struct {
+	crud[core.User]
+	next repository.UserRepository
+}
+
+func (r users) FindByEmail(ctx context.Context, email string) (core.User, error) {
+	return call(ctx, "UserRepository.FindByEmail", func(ctx context.Context) (core.User, error) { return r.next.FindByEmail(ctx, email) })
+}
+
+func (r users) HasDependents(ctx context.Context, id int64) (bool, error) {
+	return call(ctx, "UserRepository.HasDependents", func(ctx context.Context) (bool, error) { return r.next.HasDependents(ctx, id) })
+}
+
+func (r users) DeleteCascade(ctx context.Context, user *core.User) error {
+	return exec(ctx, "UserRepository.DeleteCascade", func(ctx context.Context) error { return r.next.DeleteCascade(ctx, user) })
+}
+
+type photos struct {
+	crud[core.Photo]
+	next repository.PhotoRepository
+}
+
+func (r photos) HasDependents(ctx context.Context, id int64) (bool, error) {
+	return call(ctx, "PhotoRepository.HasDependents", func(ctx context.Context) (bool, error) { return r.next.HasDependents(ctx, id) })
+}
+
+func (r photos) DeleteCascade(ctx context.Context, photo *core.Photo) error {
+	return exec(ctx, "PhotoRepository.DeleteCascade", func(ctx context.Context) error { return r.next.DeleteCascade(ctx, photo) })
+}
+
+type comments struct {
+	crud[core.Comment]
+}
+
+type socialMedia struct {
+	crud[core.SocialMedia]
+}
+
+type refreshTokens struct {
+	next repository.RefreshTokenRepository
+}
+
+func (r refreshTokens) Create(ctx context.Context, token *core.RefreshToken) error {
+	return exec(ctx, "RefreshTokenRepository.Create", func(ctx context.Context) error { return r.next.Create(ctx, token) })
+}
+
+func (r refreshTokens) FindByHash(ctx context.Context, hash string) (core.RefreshToken, error) {
+	return call(ctx, "RefreshTokenRepository.FindByHash", func(ctx context.Context) (core.RefreshToken, error) { return r.next.FindByHash(ctx, hash) })
+}
+
+func (r refreshTokens) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	return exec(ctx, "RefreshTokenRepository.MarkUsed", func(ctx context.Context) error { return r.next.MarkUsed(ctx, id, at) })
+}
+
+func (r refreshTokens) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	return exec(ctx, "RefreshTokenRepository.RevokeFamily", func(ctx context.Context) error { return r.next.RevokeFamily(ctx, familyID, at) })
+}
+
+func (r refreshTokens) FindActiveByUser(ctx context.Context, userID int64, now time.Time) ([]core.RefreshToken, error) {
+	return call(ctx, "RefreshTokenRepository.FindActiveByUser", func(ctx context.Context) ([]core.RefreshToken, error) {
+		return r.next.FindActiveByUser(ctx, userID, now)
+	})
+}
+
+type passwordResetTokens struct {
+	next repository.PasswordResetTokenRepository
+}
+
+func (r passwordResetTokens) Create(ctx context.Context, token *core.PasswordResetToken) error {
+	return exec(ctx, "PasswordResetTokenRepository.Create", func(ctx context.Context) error { return r.next.Create(ctx, token) })
+}
+
+func (r passwordResetTokens) FindByHash(ctx context.Context, hash string) (core.PasswordResetToken, error) {
+	return call(ctx, "PasswordResetTokenRepository.FindByHash", func(ctx context.Context) (core.PasswordResetToken, error) {
+		return r.next.FindByHash(ctx, hash)
+	})
+}
+
+func (r passwordResetTokens) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	return exec(ctx, "PasswordResetTokenRepository.MarkUsed", func(ctx context.Context) error { return r.next.MarkUsed(ctx, id, at) })
+}
+
+func (r passwordResetTokens) InvalidateForUser(ctx context.Context, userID int64, at time.Time) error {
+	return exec(ctx, "PasswordResetTokenRepository.InvalidateForUser", func(ctx context.Context) error {
+		return r.next.InvalidateForUser(ctx, userID, at)
+	})
+}
+
+type twoFactorChallenges struct {
+	next repository.TwoFactorChallengeRepository
+}
+
+func (r twoFactorChallenges) Create(ctx context.Context, challenge *core.TwoFactorChallenge) error {
+	return exec(ctx, "TwoFactorChallengeRepository.Create", func(ctx context.Context) error { return r.next.Create(ctx, challenge) })
+}
+
+func (r twoFactorChallenges) FindByHash(ctx context.Context, hash string) (core.TwoFactorChallenge, error) {
+	return call(ctx, "TwoFactorChallengeRepository.FindByHash", func(ctx context.Context) (core.TwoFactorChallenge, error) {
+		return r.next.FindByHash(ctx, hash)
+	})
+}
+
+func (r twoFactorChallenges) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	return exec(ctx, "TwoFactorChallengeRepository.MarkUsed", func(ctx context.Context) error { return r.next.MarkUsed(ctx, id, at) })
+}
+
+func (r twoFactorChallenges) RecordFailure(ctx context.Context, id int64, maxFailures int, at time.Time) error {
+	return exec(ctx, "TwoFactorChallengeRepository.RecordFailure", func(ctx context.Context) error {
+		return r.next.RecordFailure(ctx, id, maxFailures, at)
+	})
+}
+
+type recoveryCodes struct {
+	next repository.RecoveryCodeRepository
+}
+
+func (r recoveryCodes) Replace(ctx context.Context, userID int64, codes []core.RecoveryCode) error {
+	return exec(ctx, "RecoveryCodeRepository.Replace", func(ctx context.Context) error { return r.next.Replace(ctx, userID, codes) })
+}
+
+func (r recoveryCodes) FindUnusedByUser(ctx context.Context, userID int64) ([]core.RecoveryCode, error) {
+	return call(ctx, "RecoveryCodeRepository.FindUnusedByUser", func(ctx context.Context) ([]core.RecoveryCode, error) {
+		return r.next.FindUnusedByUser(ctx, userID)
+	})
+}
+
+func (r recoveryCodes) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	return exec(ctx, "RecoveryCodeRepository.MarkUsed", func(ctx context.Context) error { return r.next.MarkUsed(ctx, id, at) })
+}
+
+func (r recoveryCodes) DeleteByUser(ctx context.Context, userID int64) error {
+	return exec(ctx, "RecoveryCodeRepository.DeleteByUser", func(ctx context.Context) error { return r.next.DeleteByUser(ctx, userID) })
+}
+
+type loginAttempts struct {
+	next repository.LoginAttemptRepository
+}
+
+func (r loginAttempts) FindByKey(ctx context.Context, key string) (core.LoginAttempt, error) {
+	return call(ctx, "LoginAttemptRepository.FindByKey", func(ctx context.Context) (core.LoginAttempt, error) { return r.next.FindByKey(ctx, key) })
+}
+
+func (r loginAttempts) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (core.LoginAttempt, error) {
+	return call(ctx, "LoginAttemptRepository.RecordFailure", func(ctx context.Context) (core.LoginAttempt, error) {
+		return r.next.RecordFailure(ctx, key, at, window)
+	})
+}
+
+func (r loginAttempts) Lock(ctx context.Context, key string, until time.Time) error {
+	return exec(ctx, "LoginAttemptRepository.Lock", func(ctx context.Context) error { return r.next.Lock(ctx, key, until) })
+}
+
+func (r loginAttempts) Reset(ctx context.Context, key string) error {
+	return exec(ctx, "LoginAttemptRepository.Reset", func(ctx context.Context) error { return r.next.Reset(ctx, key) })
+}
@@ -0,0 +1,60 @@
+// Package traced decorates repositories with a span around every call, so a trace shows which
+// repository method issued each query.
+package traced
+
+import (
+	"context"
+	"errors"
+
+	"finalproject/core"
+	"finalproject/repository"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("finalproject/repository")
+
+// Wrap returns repos with every repository traced.
+func Wrap(repos repository.Repositories) repository.Repositories {
+	return repository.Repositories{
+		Users:       users{crud[core.User]{repos.Users, "UserRepository"}, repos.Users},
+		Photos:      photos{crud[core.Photo]{repos.Photos, "PhotoRepository"}, repos.Photos},
+		Comments:    comments{crud[core.Comment]{repos.Comments, "CommentRepository"}},
+		SocialMedia: socialMedia{crud[core.SocialMedia]{repos.SocialMedia, "SocialMediaRepository"}},
+
+		RefreshTokens:       refreshTokens{repos.RefreshTokens},
+		PasswordResetTokens: passwordResetTokens{repos.PasswordResetTokens},
+		TwoFactorChallenges: twoFactorChallenges{repos.TwoFactorChallenges},
+		RecoveryCodes:       recoveryCodes{repos.RecoveryCodes},
+		LoginAttempts:       loginAttempts{repos.LoginAttempts},
+	}
+}
+
+// call runs fn inside a span named name.
+func call[T any](ctx context.Context, name string, fn func(context.Context) (T, error)) (T, error) {
+	ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+
+	result, err := fn(ctx)
+	record(span, err)
+	return result, err
+}
+
+// exec is call for methods returning only an error.
+func exec(ctx context.Context, name string, fn func(context.Context) error) error {
+	_, err := call(ctx, name, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// record marks the span as failed, except for lookups that found nothing, which callers expect.
+func record(span trace.Span, err error) {
+	if err == nil || errors.Is(err, repository.ErrNotFound) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"finalproject/core"
+	"finalproject/helpers"
+	"finalproject/repository"
+)
+
+var (
+	seedFirstNames = []string{
+		"Andi", "Budi", "Citra", "Dewi", "Eko", "Fitri", "Gilang", "Hana", "Indra", "Joko",
+		"Kartika", "Lestari", "Made", "Nadia", "Putri", "Rizky", "Sari", "Teguh", "Wulan", "Yusuf",
+		"Alice", "Ben", "Chloe", "Daniel", "Emma", "Felix", "Grace", "Henry", "Isla", "Jack",
+	}
+	seedLastNames = []string{
+		"Pratama", "Saputra", "Wijaya", "Santoso", "Hidayat", "Nugroho", "Kusuma", "Setiawan", "Halim", "Siregar",
+		"Smith", "Jones", "Taylor", "Brown", "Wilson", "Evans", "Walker", "Wright", "Hughes", "Clarke",
+	}
+	seedEmailDomains = []string{"gmail.com", "yahoo.co.id", "outlook.com", "proton.me", "example.org"}
+	seedPlaces       = []string{
+		"Bali", "Bandung", "Yogyakarta", "Lake Toba", "Bromo", "Labuan Bajo", "Raja Ampat", "Jakarta",
+		"Lombok", "Malang", "Kyoto", "Lisbon", "Reykjavik", "Cape Town", "Patagonia",
+	}
+	seedSubjects = []string{
+		"Sunrise over", "Street food in", "Rainy evening in", "Weekend hike near", "Old town of",
+		"Golden hour at", "Night market in", "Coffee break in", "Beach day at", "Morning mist over",
+	}
+	seedCaptions = []string{
+		"Worth waking up at 4am for.",
+		"Shot on my phone, no filter.",
+		"Can't wait to come back here.",
+		"The light was unreal today.",
+		"Found this spot by accident.",
+		"",
+	}
+	seedComments = []string{
+		"Wow, stunning shot!", "Keren banget!", "Where exactly is this?", "Mantap, lokasinya di mana?",
+		"Love the colours.", "This is my favourite place too.", "Great composition.", "Bikin pengen liburan.",
+		"What camera did you use?", "Adding this to my travel list.", "So peaceful.", "Nice one!",
+	}
+	seedSocialMedia = []struct{ name, url string }{
+		{"Instagram", "https://instagram.com/%s"},
+		{"X", "https://x.com/%s"},
+		{"GitHub", "https://github.com/%s"},
+	}
+)
+
+// seed fills the database with fake but realistic users, photos and comments for development and demos.
+// Every user shares one password and a verified email; usernames that already exist are skipped,
+// so seeding can be repeated.
+func seed(ctx context.Context, app *app, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	users := flags.Int("users", 20, "number of users to create")
+	photos := flags.Int("photos", 3, "maximum number of photos per user")
+	comments := flags.Int("comments", 5, "maximum number of comments per photo")
+	password := flags.String("password", "password", "password of every seeded user")
+	randomSeed := flags.Int64("seed", time.Now().UnixNano(), "random seed, for reproducible data")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *users < 0 || *photos < 0 || *comments < 0 {
+		return usageErrorf("-users, -photos and -comments must not be negative")
+	}
+	if err := core.ValidatePassword(*password); err != nil {
+		return usageErrorf("invalid -password: %v", err)
+	}
+
+	passwords, err := helpers.NewPasswords(app.cfg.Passwords.Hasher)
+	if err != nil {
+		return err
+	}
+	// 1. Hash once; hashing per user would dominate the run time
+	hash, err := passwords.Hash(*password)
+	if err != nil {
+		return err
+	}
+
+	repos := app.repositories()
+	rng := rand.New(rand.NewSource(*randomSeed))
+	now := time.Now()
+
+	// 2. Create the users, each with a social media account
+	var created []core.User
+	for i := 0; i < *users; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		first, last := pick(rng, seedFirstNames), pick(rng, seedLastNames)
+		username := fmt.Sprintf("%s.%s%d", strings.ToLower(first), strings.ToLower(last), rng.Intn(1000))
+		user := core.User{
+			Username:        username,
+			Email:           username + "@" + pick(rng, seedEmailDomains),
+			Password:        hash,
+			Age:             18 + rng.Intn(50),
+			ProfileImageURL: fmt.Sprintf("https://i.pravatar.cc/300?u=%s", username),
+			Role:            core.RoleUser,
+			EmailVerifiedAt: &now,
+		}
+		if err := user.Validate(); err != nil {
+			return fmt.Errorf("seeded user %s: %w", username, err)
+		}
+		if err := repos.Users.Create(ctx, &user); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				continue
+			}
+			return err
+		}
+		created = append(created, user)
+
+		account := pick(rng, seedSocialMedia)
+		socialMedia := core.SocialMedia{
+			Name:           account.name,
+			SocialMediaURL: fmt.Sprintf(account.url, username),
+			UserID:         user.ID,
+		}
+		if err := repos.SocialMedia.Create(ctx, &socialMedia); err != nil {
+			return err
+		}
+	}
+	if len(created) == 0 {
+		fmt.Println("Seeded 0 users")
+		return nil
+	}
+
+	// 3. Give each user some photos, commented on by other seeded users
+	photoCount, commentCount := 0, 0
+	for _, user := range created {
+		for j := rng.Intn(*photos + 1); j > 0; j-- {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			photo := core.Photo{
+				Title:    pick(rng, seedSubjects) + " " + pick(rng, seedPlaces),
+				Caption:  pick(rng, seedCaptions),
+				PhotoURL: fmt.Sprintf("https://picsum.photos/seed/%d/1080/1080", rng.Int63()),
+				UserID:   user.ID,
+			}
+			if err := photo.Validate(); err != nil {
+				return fmt.Errorf("seeded photo: %w", err)
+			}
+			if err := repos.Photos.Create(ctx, &photo); err != nil {
+				return err
+			}
+			photoCount++
+
+			for k := rng.Intn(*comments + 1); k > 0; k-- {
+				comment := core.Comment{
+					UserID:  created[rng.Intn(len(created))].ID,
+					PhotoID: photo.ID,
+					Message: pick(rng, seedComments),
+				}
+				if err := repos.Comments.Create(ctx, &comment); err != nil {
+					return err
+				}
+				commentCount++
+			}
+		}
+	}
+
+	fmt.Printf("Seeded %d users, %d photos and %d comments (password %q, seed %d)\n",
+		len(created), photoCount, commentCount, *password, *randomSeed)
+	return nil
+}
+
+func pick[T any](rng *rand.Rand, values []T) T {
+	return values[rng.Intn(len(values))]
+}
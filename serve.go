@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"finalproject/database"
+	"finalproject/handler"
+	"finalproject/metrics"
+	"finalproject/service"
+)
+
+// serve runs the HTTP server until ctx is cancelled, then stops accepting connections, lets
+// in-flight requests finish within the shutdown timeout and stops the background workers.
+func serve(ctx context.Context, app *app, args []string) error {
+	if len(args) > 0 {
+		return usageErrorf("serve takes no arguments")
+	}
+
+	app.metrics = metrics.New()
+	if err := app.metrics.ObserveDatabase(app.db); err != nil {
+		return fmt.Errorf("failed to instrument database: %w", err)
+	}
+
+	services, err := app.services()
+	if err != nil {
+		return err
+	}
+	sqlDB, err := app.db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	migrator, err := database.NewMigrator(app.db)
+	if err != nil {
+		return err
+	}
+
+	// 1. Start the background workers; they outlive ctx so they keep running while requests drain
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	defer func() {
+		stopWorkers()
+		workers.Wait()
+	}()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		app.keys.RunRotation(workerCtx, app.cfg.JWT.RotationInterval, service.SigningKeyRetention)
+	}()
+
+	// 2. Start the server
+	health := handler.NewHealthHandler(map[string]handler.ReadinessCheck{
+		"database":   sqlDB.PingContext,
+		"migrations": migrator.Current,
+	})
+	server := &http.Server{
+		Addr: ":" + strconv.Itoa(app.cfg.Server.Port),
+		Handler: handler.NewRouter(services, handler.RouterConfig{
+			Health:         health,
+			Logger:         app.logger,
+			LogSampleRate:  app.cfg.Log.SampleRate,
+			Metrics:        app.metrics,
+			TrustedProxies: app.cfg.Server.Proxies(),
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServe()
+	}()
+	slog.Info("listening", "addr", server.Addr)
+
+	// Metrics are served on their own port, which is not published like the API port
+	if port := app.cfg.Server.MetricsPort; port != 0 {
+		admin := &http.Server{
+			Addr:              ":" + strconv.Itoa(port),
+			Handler:           handler.MetricsHandler(app.metrics),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			if err := admin.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				served <- fmt.Errorf("metrics listener: %w", err)
+			}
+		}()
+		defer admin.Close()
+		slog.Info("serving metrics", "addr", admin.Addr)
+	}
+
+	// 3. Wait for a shutdown signal, or for the server to fail on its own
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	// 4. Fail readiness and keep serving for the drain delay, so load balancers and probes see
+	// /readyz fail and stop routing here before the listener closes
+	delay, timeout := app.cfg.Server.DrainDelay, app.cfg.Server.ShutdownTimeout
+	slog.Info("shutting down, draining requests", "drain_delay", delay.String(), "timeout", timeout.String())
+	health.Drain()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), delay+timeout)
+	defer cancel()
+	drained := time.NewTimer(delay)
+	defer drained.Stop()
+	select {
+	case <-drained.C:
+	case err := <-served:
+		return err
+	case <-shutdownCtx.Done():
+	}
+
+	// 5. Stop accepting connections and let the in-flight requests finish
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("failed to drain requests: %w", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	// Reset emails are sent after their request was answered
+	services.PasswordReset.Wait()
+	slog.Info("server stopped")
+	return nil
+}
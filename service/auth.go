@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"finalproject/core"
+	"finalproject/helpers"
+	"finalproject/repository"
+)
+
+// RefreshTokenTTL is how long a refresh token can be exchanged after it was minted.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens.
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated token is replayed; the whole family is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// TokenPair is what a successful login or refresh hands back to the client.
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// ClientInfo describes the device a session was created from.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is the user-facing view of a refresh token family.
+type Session struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"userAgent"`
+	ClientIP  string    `json:"clientIp"`
+	LastUsed  time.Time `json:"lastUsedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type AuthService struct {
+	users         repository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
+	keys          *helpers.KeyRing
+	events        EventRecorder
+}
+
+func NewAuthService(users repository.UserRepository, refreshTokens repository.RefreshTokenRepository, keys *helpers.KeyRing, events EventRecorder) *AuthService {
+	return &AuthService{users: users, refreshTokens: refreshTokens, keys: keys, events: events}
+}
+
+// VerifyAccessToken checks an access token against the key ring and returns the user it was issued for.
+func (s *AuthService) VerifyAccessToken(token string) (string, error) {
+	return s.keys.VerifyToken(token)
+}
+
+// JWKS publishes the public signing keys so other services can verify mygram tokens.
+func (s *AuthService) JWKS() helpers.JWKSet {
+	return s.keys.JWKS()
+}
+
+// IssueTokens starts a new session for the user, completing a login.
+func (s *AuthService) IssueTokens(ctx context.Context, user core.User, client ClientInfo) (TokenPair, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.IssueTokens")
+	defer span.End()
+
+	familyID, err := helpers.GenerateOpaqueToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	tokens, err := s.issue(ctx, user, familyID, client)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	s.events.Record(ctx, EventLoginSucceeded)
+	return tokens, nil
+}
+
+// Refresh rotates a refresh token. Presenting a token that was already rotated revokes its whole family,
+// since either the legitimate client or an attacker is holding a stolen copy.
+func (s *AuthService) Refresh(ctx context.Context, rawToken string, client ClientInfo) (TokenPair, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Refresh")
+	defer span.End()
+
+	now := time.Now()
+
+	token, err := s.refreshTokens.FindByHash(ctx, helpers.HashToken(rawToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		return TokenPair{}, ErrInvalidRefreshToken
+	} else if err != nil {
+		return TokenPair{}, err
+	}
+
+	if token.UsedAt != nil {
+		if err := s.refreshTokens.RevokeFamily(ctx, token.FamilyID, now); err != nil {
+			return TokenPair{}, err
+		}
+		return TokenPair{}, ErrRefreshTokenReused
+	}
+	if !token.Active(now) {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	// Lost the race against a concurrent refresh with the same token: treat it as reuse
+	if err := s.refreshTokens.MarkUsed(ctx, token.ID, now); errors.Is(err, repository.ErrNotFound) {
+		if err := s.refreshTokens.RevokeFamily(ctx, token.FamilyID, now); err != nil {
+			return TokenPair{}, err
+		}
+		return TokenPair{}, ErrRefreshTokenReused
+	} else if err != nil {
+		return TokenPair{}, err
+	}
+
+	// The account may have been deleted since the session started; reloading it also picks up role changes
+	user, err := s.users.FindByID(ctx, token.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return TokenPair{}, ErrInvalidRefreshToken
+	} else if err != nil {
+		return TokenPair{}, err
+	}
+
+	return s.issue(ctx, user, token.FamilyID, client)
+}
+
+// Logout revokes the session the refresh token belongs to.
+func (s *AuthService) Logout(ctx context.Context, rawToken string) error {
+	ctx, span := tracer.Start(ctx, "AuthService.Logout")
+	defer span.End()
+
+	token, err := s.refreshTokens.FindByHash(ctx, helpers.HashToken(rawToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidRefreshToken
+	} else if err != nil {
+		return err
+	}
+	return s.refreshTokens.RevokeFamily(ctx, token.FamilyID, time.Now())
+}
+
+// Sessions lists the user's active sessions, most recently used first.
+func (s *AuthService) Sessions(ctx context.Context, userID int64) ([]Session, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Sessions")
+	defer span.End()
+
+	tokens, err := s.refreshTokens.FindActiveByUser(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, Session{
+			ID:        token.FamilyID,
+			UserAgent: token.UserAgent,
+			ClientIP:  token.ClientIP,
+			LastUsed:  token.CreatedAt,
+			ExpiresAt: token.ExpiresAt,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends one of the user's sessions.
+func (s *AuthService) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	ctx, span := tracer.Start(ctx, "AuthService.RevokeSession")
+	defer span.End()
+
+	sessions, err := s.Sessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			return s.refreshTokens.RevokeFamily(ctx, sessionID, time.Now())
+		}
+	}
+	return ErrNotFound
+}
+
+// RevokeAllSessions ends every session of the user, e.g. after a password change.
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID int64) error {
+	ctx, span := tracer.Start(ctx, "AuthService.RevokeAllSessions")
+	defer span.End()
+
+	sessions, err := s.Sessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := s.refreshTokens.RevokeFamily(ctx, session.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AuthService) issue(ctx context.Context, user core.User, familyID string, client ClientInfo) (TokenPair, error) {
+	accessToken, err := s.keys.GenerateToken(user.PublicID, string(user.Role))
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	rawToken, err := helpers.GenerateOpaqueToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	err = s.refreshTokens.Create(ctx, &core.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: helpers.HashToken(rawToken),
+		UserAgent: client.UserAgent,
+		ClientIP:  client.IP,
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{AccessToken: accessToken, RefreshToken: rawToken}, nil
+}
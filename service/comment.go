@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+// ErrPhotoNotFound is returned when commenting on a photo that does not exist.
+var ErrPhotoNotFound = errors.New("photo not found")
+
+type CommentService struct {
+	comments repository.CommentRepository
+	photos   repository.PhotoRepository
+	events   EventRecorder
+}
+
+func NewCommentService(comments repository.CommentRepository, photos repository.PhotoRepository, events EventRecorder) *CommentService {
+	return &CommentService{comments: comments, photos: photos, events: events}
+}
+
+func (s *CommentService) FindAll(ctx context.Context) ([]core.Comment, error) {
+	ctx, span := tracer.Start(ctx, "CommentService.FindAll")
+	defer span.End()
+
+	return s.comments.FindAll(ctx)
+}
+
+func (s *CommentService) FindByID(ctx context.Context, id int64) (core.Comment, error) {
+	ctx, span := tracer.Start(ctx, "CommentService.FindByID")
+	defer span.End()
+
+	return s.comments.FindByID(ctx, id)
+}
+
+func (s *CommentService) FindByPublicID(ctx context.Context, publicID string) (core.Comment, error) {
+	ctx, span := tracer.Start(ctx, "CommentService.FindByPublicID")
+	defer span.End()
+
+	return s.comments.FindByPublicID(ctx, publicID)
+}
+
+// Create stores the comment on the photo with the given public ID, which must exist.
+func (s *CommentService) Create(ctx context.Context, comment *core.Comment, photoID string) error {
+	ctx, span := tracer.Start(ctx, "CommentService.Create")
+	defer span.End()
+
+	photo, err := s.photos.FindByPublicID(ctx, photoID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrPhotoNotFound
+	} else if err != nil {
+		return err
+	}
+	comment.PhotoID = photo.ID
+
+	// The foreign key still catches a photo deleted in the meantime
+	err = s.comments.Create(ctx, comment)
+	if errors.Is(err, repository.ErrReferenced) {
+		return ErrPhotoNotFound
+	} else if err != nil {
+		return err
+	}
+
+	photo.User = nil
+	comment.Photo = &photo
+	s.events.Record(ctx, EventCommentCreated)
+	return nil
+}
+
+func (s *CommentService) Update(ctx context.Context, comment *core.Comment) error {
+	ctx, span := tracer.Start(ctx, "CommentService.Update")
+	defer span.End()
+
+	return s.comments.Update(ctx, comment)
+}
+
+func (s *CommentService) Delete(ctx context.Context, comment *core.Comment) error {
+	ctx, span := tracer.Start(ctx, "CommentService.Delete")
+	defer span.End()
+
+	return s.comments.Delete(ctx, comment)
+}
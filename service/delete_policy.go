@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DeletePolicy decides what happens to the content of a deleted user or photo.
+type DeletePolicy string
+
+const (
+	// DeleteCascade soft-deletes the dependents together with the record.
+	DeleteCascade DeletePolicy = "cascade"
+	// DeleteRestrict refuses to delete a record that still has dependents.
+	DeleteRestrict DeletePolicy = "restrict"
+)
+
+// ErrHasDependents is returned under DeleteRestrict when the record still has dependents.
+var ErrHasDependents = errors.New("record still has dependents")
+
+// ParseDeletePolicy accepts "cascade" or "restrict"; empty selects DeleteCascade.
+func ParseDeletePolicy(value string) (DeletePolicy, error) {
+	switch policy := DeletePolicy(value); policy {
+	case "":
+		return DeleteCascade, nil
+	case DeleteCascade, DeleteRestrict:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown delete policy %q", value)
+	}
+}
+
+// dependentDeleter is implemented by repositories of records that own other records.
+type dependentDeleter[T any] interface {
+	HasDependents(ctx context.Context, id int64) (bool, error)
+	DeleteCascade(ctx context.Context, row *T) error
+}
+
+// deleteWithPolicy deletes row according to policy.
+func deleteWithPolicy[T any](ctx context.Context, repo dependentDeleter[T], policy DeletePolicy, id int64, row *T) error {
+	if policy == DeleteRestrict {
+		hasDependents, err := repo.HasDependents(ctx, id)
+		if err != nil {
+			return err
+		}
+		if hasDependents {
+			return ErrHasDependents
+		}
+	}
+	return repo.DeleteCascade(ctx, row)
+}
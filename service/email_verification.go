@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"finalproject/core"
+	"finalproject/helpers"
+	"finalproject/mailer"
+	"finalproject/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// EmailVerificationTTL is how long an emailed verification link stays valid.
+	EmailVerificationTTL = 24 * time.Hour
+
+	emailVerificationAudience = "mygram-email-verification"
+)
+
+var (
+	// ErrInvalidVerificationToken is returned for forged, expired or outdated verification links.
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	// ErrEmailAlreadyVerified is returned when asking for a link while nothing is awaiting confirmation.
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	// ErrEmailNotVerified is returned when the verification policy blocks an action.
+	ErrEmailNotVerified = errors.New("email not verified")
+)
+
+// emailVerificationClaims binds a link to one address, so a link for a superseded pending email stops working.
+type emailVerificationClaims struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+type EmailVerificationService struct {
+	users  repository.UserRepository
+	keys   *helpers.KeyRing
+	mailer mailer.Mailer
+	appURL string
+}
+
+func NewEmailVerificationService(users repository.UserRepository, keys *helpers.KeyRing, mail mailer.Mailer, appURL string) *EmailVerificationService {
+	return &EmailVerificationService{users: users, keys: keys, mailer: mail, appURL: appURL}
+}
+
+// SendVerification emails a link confirming the pending email if there is one, otherwise the current email.
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user core.User) error {
+	ctx, span := tracer.Start(ctx, "EmailVerificationService.SendVerification")
+	defer span.End()
+
+	email := user.PendingEmail
+	if email == "" {
+		if user.EmailVerified() {
+			return ErrEmailAlreadyVerified
+		}
+		email = user.Email
+	}
+
+	now := time.Now()
+	token, err := s.keys.Sign(emailVerificationClaims{
+		UserID: user.PublicID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    helpers.TokenIssuer,
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(EmailVerificationTTL)),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/verify-email?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Confirm your mygram email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm that %s is your email address by opening the link below "+
+			"within %d hours:\n\n%s\n\nIf you didn't ask for this, you can ignore this email.\n",
+			user.Username, email, int(EmailVerificationTTL.Hours()), link),
+	})
+}
+
+// CheckEmailAvailable returns ErrEmailTaken when another account already uses email, so callers
+// can refuse a change before saving anything else.
+func (s *EmailVerificationService) CheckEmailAvailable(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "EmailVerificationService.CheckEmailAvailable")
+	defer span.End()
+
+	if _, err := s.users.FindByEmail(ctx, email); err == nil {
+		return ErrEmailTaken
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// RequestEmailChange records newEmail as pending and sends it a verification link.
+// The current email keeps working for login until the new one is confirmed.
+func (s *EmailVerificationService) RequestEmailChange(ctx context.Context, userID int64, newEmail string) error {
+	ctx, span := tracer.Start(ctx, "EmailVerificationService.RequestEmailChange")
+	defer span.End()
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if newEmail == user.Email {
+		// Changing back to the current address simply cancels the pending change
+		user.PendingEmail = ""
+		return s.users.Update(ctx, &user)
+	}
+
+	if err := s.CheckEmailAvailable(ctx, newEmail); err != nil {
+		return err
+	}
+
+	user.PendingEmail = newEmail
+	if err := s.users.Update(ctx, &user); err != nil {
+		return err
+	}
+	return s.SendVerification(ctx, user)
+}
+
+// Verify confirms the address a link was issued for. Confirming a pending email makes it the login email.
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) (core.User, error) {
+	ctx, span := tracer.Start(ctx, "EmailVerificationService.Verify")
+	defer span.End()
+
+	var claims emailVerificationClaims
+	if err := s.keys.Parse(token, &claims, jwt.WithAudience(emailVerificationAudience)); err != nil {
+		return core.User{}, ErrInvalidVerificationToken
+	}
+
+	user, err := s.users.FindByPublicID(ctx, claims.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return core.User{}, ErrInvalidVerificationToken
+	} else if err != nil {
+		return core.User{}, err
+	}
+
+	now := time.Now()
+	switch {
+	case user.PendingEmail != "" && claims.Email == user.PendingEmail:
+		user.Email = user.PendingEmail
+		user.PendingEmail = ""
+		user.EmailVerifiedAt = &now
+	case claims.Email == user.Email && !user.EmailVerified():
+		user.EmailVerifiedAt = &now
+	case claims.Email == user.Email:
+		return user, nil // Clicking the same link twice is harmless
+	default:
+		return core.User{}, ErrInvalidVerificationToken
+	}
+
+	err = s.users.Update(ctx, &user)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return core.User{}, ErrEmailTaken
+	}
+	return user, err
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"errors"
+
+	"finalproject/repository"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = repository.ErrNotFound
+	// ErrEmailTaken is returned when registering an email that already exists.
+	ErrEmailTaken = errors.New("email already exists")
+	// ErrUsernameTaken is returned when renaming a user to a username that already exists.
+	ErrUsernameTaken = errors.New("username already exists")
+)
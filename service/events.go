@@ -0,0 +1,24 @@
+package service
+
+import "context"
+
+// Event is a business event worth counting, such as a registration.
+type Event string
+
+const (
+	EventRegistered     Event = "registered"
+	EventLoginSucceeded Event = "login_succeeded" // Tokens were issued after the password and, if enabled, the second factor
+	EventLoginFailed    Event = "login_failed"    // A wrong password or second factor
+	EventPhotoCreated   Event = "photo_created"
+	EventCommentCreated Event = "comment_created"
+)
+
+// EventRecorder observes business events, e.g. to export them as metrics.
+type EventRecorder interface {
+	Record(ctx context.Context, event Event)
+}
+
+// noEvents is the EventRecorder used when none is configured.
+type noEvents struct{}
+
+func (noEvents) Record(context.Context, Event) {}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"finalproject/core"
+	"finalproject/mailer"
+	"finalproject/repository"
+)
+
+// LockoutPolicy tunes how LoginGuard slows down and locks out repeated failed logins.
+type LockoutPolicy struct {
+	// Window is how long a failure counts; a failure after a quiet Window starts the count over.
+	Window time.Duration
+	// FreeAttempts failures are allowed before every further attempt has to wait BaseDelay,
+	// doubling with each failure up to MaxDelay.
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	// AccountThreshold and IPThreshold failures lock the account or client IP for LockoutDuration.
+	AccountThreshold int
+	IPThreshold      int
+	LockoutDuration  time.Duration
+	// ResetsPerAddress and ResetsPerIP cap the password reset requests per Window, and separately
+	// the verification emails sent on request.
+	ResetsPerAddress int
+	ResetsPerIP      int
+}
+
+// DefaultLockoutPolicy is used for every zero field of Dependencies.Lockout.
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		Window:           15 * time.Minute,
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         30 * time.Second,
+		AccountThreshold: 10,
+		IPThreshold:      50,
+		LockoutDuration:  15 * time.Minute,
+		ResetsPerAddress: 3,
+		ResetsPerIP:      20,
+	}
+}
+
+var (
+	// ErrResetThrottled is wrapped by the *ThrottledError of AllowReset, telling it apart from a throttled login.
+	ErrResetThrottled = errors.New("too many password reset requests")
+	// ErrVerificationThrottled is wrapped by the *ThrottledError of AllowVerificationEmail.
+	ErrVerificationThrottled = errors.New("too many verification emails")
+)
+
+// ThrottledError tells the client how long to wait before the next login attempt.
+type ThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool  // true for a lockout, false for a progressive delay
+	Err        error // What was throttled when it was not a login, e.g. ErrResetThrottled
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s, retry in %s", e.Err, e.RetryAfter)
+	}
+	if e.Locked {
+		return fmt.Sprintf("too many failed attempts, locked for %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("too many failed attempts, retry in %s", e.RetryAfter)
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// LoginGuard tracks failed logins per account and per client IP.
+type LoginGuard struct {
+	attempts repository.LoginAttemptRepository
+	users    repository.UserRepository
+	mailer   mailer.Mailer
+	policy   LockoutPolicy
+	events   EventRecorder
+}
+
+func NewLoginGuard(attempts repository.LoginAttemptRepository, users repository.UserRepository, mail mailer.Mailer, policy LockoutPolicy, events EventRecorder) *LoginGuard {
+	defaults := DefaultLockoutPolicy()
+	if policy.Window == 0 {
+		policy.Window = defaults.Window
+	}
+	if policy.FreeAttempts == 0 {
+		policy.FreeAttempts = defaults.FreeAttempts
+	}
+	if policy.BaseDelay == 0 {
+		policy.BaseDelay = defaults.BaseDelay
+	}
+	if policy.MaxDelay == 0 {
+		policy.MaxDelay = defaults.MaxDelay
+	}
+	if policy.AccountThreshold == 0 {
+		policy.AccountThreshold = defaults.AccountThreshold
+	}
+	if policy.IPThreshold == 0 {
+		policy.IPThreshold = defaults.IPThreshold
+	}
+	if policy.LockoutDuration == 0 {
+		policy.LockoutDuration = defaults.LockoutDuration
+	}
+	if policy.ResetsPerAddress == 0 {
+		policy.ResetsPerAddress = defaults.ResetsPerAddress
+	}
+	if policy.ResetsPerIP == 0 {
+		policy.ResetsPerIP = defaults.ResetsPerIP
+	}
+	return &LoginGuard{attempts: attempts, users: users, mailer: mail, policy: policy, events: events}
+}
+
+func accountKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check returns a *ThrottledError when the account or the client IP has to wait. An empty email only checks the IP.
+func (g *LoginGuard) Check(ctx context.Context, email, ip string) error {
+	ctx, span := tracer.Start(ctx, "LoginGuard.Check")
+	defer span.End()
+
+	now := time.Now()
+
+	var wait *ThrottledError
+	for _, key := range g.keys(email, ip) {
+		attempt, err := g.attempts.FindByKey(ctx, key)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if throttled := g.throttle(attempt, now); throttled != nil && (wait == nil || throttled.RetryAfter > wait.RetryAfter) {
+			wait = throttled
+		}
+	}
+
+	if wait != nil {
+		return wait
+	}
+	return nil
+}
+
+// Failure records a failed attempt, locking the account or IP once its threshold is reached.
+// Reaching the account threshold emails the account owner.
+func (g *LoginGuard) Failure(ctx context.Context, email, ip string) error {
+	ctx, span := tracer.Start(ctx, "LoginGuard.Failure")
+	defer span.End()
+
+	g.events.Record(ctx, EventLoginFailed)
+	now := time.Now()
+
+	for _, key := range g.keys(email, ip) {
+		attempt, err := g.attempts.RecordFailure(ctx, key, now, g.policy.Window)
+		if err != nil {
+			return err
+		}
+
+		threshold := g.policy.IPThreshold
+		if key != ipKey(ip) {
+			threshold = g.policy.AccountThreshold
+		}
+		if attempt.Failures < threshold || attempt.Locked(now) {
+			continue
+		}
+
+		if err := g.attempts.Lock(ctx, key, now.Add(g.policy.LockoutDuration)); err != nil {
+			return err
+		}
+		if key != ipKey(ip) {
+			g.notifyLockout(ctx, email, attempt.Failures)
+		}
+	}
+	return nil
+}
+
+// Success clears the account counter. The IP counter is kept, so logging into one's own account
+// does not reset the budget for guessing others.
+func (g *LoginGuard) Success(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "LoginGuard.Success")
+	defer span.End()
+
+	return g.attempts.Reset(ctx, accountKey(email))
+}
+
+// Unlock lifts the lockout and failure count of an account.
+func (g *LoginGuard) Unlock(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "LoginGuard.Unlock")
+	defer span.End()
+
+	return g.attempts.Reset(ctx, accountKey(email))
+}
+
+// UnlockIP lifts the lockout and failure count of a client IP.
+func (g *LoginGuard) UnlockIP(ctx context.Context, ip string) error {
+	ctx, span := tracer.Start(ctx, "LoginGuard.UnlockIP")
+	defer span.End()
+
+	return g.attempts.Reset(ctx, ipKey(ip))
+}
+
+// AllowReset counts a password reset request and returns a *ThrottledError wrapping ErrResetThrottled
+// once the address or the client IP made too many within the window. Both are always counted, and
+// unknown addresses alike, so the limit reveals nothing about which accounts exist.
+func (g *LoginGuard) AllowReset(ctx context.Context, email, ip string) error {
+	ctx, span := tracer.Start(ctx, "LoginGuard.AllowReset")
+	defer span.End()
+
+	return g.allowEmail(ctx, "reset:", email, ip, ErrResetThrottled)
+}
+
+// AllowVerificationEmail counts a request to resend the verification email to the address, with the
+// same limits as AllowReset, and returns a *ThrottledError wrapping ErrVerificationThrottled over them.
+func (g *LoginGuard) AllowVerificationEmail(ctx context.Context, email, ip string) error {
+	ctx, span := tracer.Start(ctx, "LoginGuard.AllowVerificationEmail")
+	defer span.End()
+
+	return g.allowEmail(ctx, "verify:", email, ip, ErrVerificationThrottled)
+}
+
+// allowEmail counts one email sent to the address on behalf of the client IP, under keys with the given prefix.
+func (g *LoginGuard) allowEmail(ctx context.Context, prefix, email, ip string, reason error) error {
+	now := time.Now()
+	limits := []struct {
+		key   string
+		limit int
+	}{
+		{prefix + ipKey(ip), g.policy.ResetsPerIP},
+		{prefix + accountKey(email), g.policy.ResetsPerAddress},
+	}
+
+	throttled := false
+	for _, limit := range limits {
+		attempt, err := g.attempts.RecordFailure(ctx, limit.key, now, g.policy.Window)
+		if err != nil {
+			return err
+		}
+		throttled = throttled || attempt.Failures > limit.limit
+	}
+	if throttled {
+		return &ThrottledError{RetryAfter: g.policy.Window, Err: reason}
+	}
+	return nil
+}
+
+func (g *LoginGuard) keys(email, ip string) []string {
+	keys := []string{ipKey(ip)}
+	if email != "" {
+		keys = append(keys, accountKey(email))
+	}
+	return keys
+}
+
+// throttle returns how long attempt has to wait at now, or nil when it may proceed.
+func (g *LoginGuard) throttle(attempt core.LoginAttempt, now time.Time) *ThrottledError {
+	if attempt.Locked(now) {
+		return &ThrottledError{RetryAfter: attempt.LockedUntil.Sub(now), Locked: true}
+	}
+
+	// Failures older than the window no longer count
+	if now.Sub(attempt.LastFailureAt) > g.policy.Window || attempt.Failures < g.policy.FreeAttempts {
+		return nil
+	}
+
+	exponent := float64(attempt.Failures - g.policy.FreeAttempts)
+	delay := time.Duration(float64(g.policy.BaseDelay) * math.Pow(2, exponent))
+	if delay > g.policy.MaxDelay || delay <= 0 {
+		delay = g.policy.MaxDelay
+	}
+
+	if wait := attempt.LastFailureAt.Add(delay).Sub(now); wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func (g *LoginGuard) notifyLockout(ctx context.Context, email string, failures int) {
+	user, err := g.users.FindByEmail(ctx, email)
+	if err != nil {
+		return // Unknown emails are locked too, but there is nobody to tell
+	}
+
+	err = g.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your mygram account was temporarily locked",
+		Body: fmt.Sprintf("Hi %s,\n\nWe locked your mygram account for %d minutes after %d failed login attempts.\n"+
+			"If this wasn't you, consider resetting your password once the lock expires.\n",
+			user.Username, int(g.policy.LockoutDuration.Minutes()), failures),
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to send lockout notification", "user_id", user.PublicID, "error", err)
+	}
+}
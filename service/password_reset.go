@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+
+	"finalproject/core"
+	"finalproject/helpers"
+	"finalproject/mailer"
+	"finalproject/repository"
+)
+
+// PasswordResetTTL is how long an emailed reset link stays valid.
+const PasswordResetTTL = time.Hour
+
+// ErrInvalidResetToken is returned for unknown, expired or already used reset tokens.
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+type PasswordResetService struct {
+	users       repository.UserRepository
+	resetTokens repository.PasswordResetTokenRepository
+	passwords   *helpers.Passwords
+	auth        *AuthService
+	mailer      mailer.Mailer
+	appURL      string
+
+	pending sync.WaitGroup // Reset emails still being sent
+}
+
+func NewPasswordResetService(users repository.UserRepository, resetTokens repository.PasswordResetTokenRepository, passwords *helpers.Passwords, auth *AuthService, mail mailer.Mailer, appURL string) *PasswordResetService {
+	return &PasswordResetService{
+		users:       users,
+		resetTokens: resetTokens,
+		passwords:   passwords,
+		auth:        auth,
+		mailer:      mail,
+		appURL:      appURL,
+	}
+}
+
+// Forgot emails a reset link to the user in the background and returns at once. Unknown emails are
+// silently ignored and delivery failures are only logged, so neither the response nor its timing
+// reveals whether an account exists.
+func (s *PasswordResetService) Forgot(ctx context.Context, email string) {
+	ctx, span := tracer.Start(ctx, "PasswordResetService.Forgot")
+	defer span.End()
+
+	// The request may end before the email is sent; keep its trace and log attributes, not its deadline
+	ctx = context.WithoutCancel(ctx)
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		if err := s.sendResetLink(ctx, email); err != nil {
+			slog.ErrorContext(ctx, "failed to send password reset email", "error", err)
+		}
+	}()
+}
+
+// Wait blocks until the reset emails queued by Forgot are sent, e.g. before the process exits.
+func (s *PasswordResetService) Wait() {
+	s.pending.Wait()
+}
+
+func (s *PasswordResetService) sendResetLink(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// Only the most recent link works
+	now := time.Now()
+	if err := s.resetTokens.InvalidateForUser(ctx, user.ID, now); err != nil {
+		return err
+	}
+
+	rawToken, err := helpers.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	err = s.resetTokens.Create(ctx, &core.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: helpers.HashToken(rawToken),
+		ExpiresAt: now.Add(PasswordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/reset-password?token=" + url.QueryEscape(rawToken)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your mygram password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your mygram account.\n"+
+			"Open the link below within %d minutes to choose a new one:\n\n%s\n\n"+
+			"If it wasn't you, you can ignore this email.\n",
+			user.Username, int(PasswordResetTTL.Minutes()), link),
+	})
+}
+
+// Reset redeems a reset token, sets the new password and ends every existing session of the user.
+func (s *PasswordResetService) Reset(ctx context.Context, rawToken, newPassword string) error {
+	ctx, span := tracer.Start(ctx, "PasswordResetService.Reset")
+	defer span.End()
+
+	now := time.Now()
+
+	token, err := s.resetTokens.FindByHash(ctx, helpers.HashToken(rawToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidResetToken
+	} else if err != nil {
+		return err
+	}
+	if !token.Active(now) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.users.FindByID(ctx, token.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidResetToken
+	} else if err != nil {
+		return err
+	}
+
+	if err := s.resetTokens.MarkUsed(ctx, token.ID, now); errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidResetToken
+	} else if err != nil {
+		return err
+	}
+
+	user.Password, err = s.passwords.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.users.Update(ctx, &user); err != nil {
+		return err
+	}
+
+	return s.auth.RevokeAllSessions(ctx, user.ID)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+type PhotoService struct {
+	photos               repository.PhotoRepository
+	requireVerifiedEmail bool
+	deletePolicy         DeletePolicy
+	events               EventRecorder
+}
+
+func NewPhotoService(photos repository.PhotoRepository, requireVerifiedEmail bool, deletePolicy DeletePolicy, events EventRecorder) *PhotoService {
+	return &PhotoService{photos: photos, requireVerifiedEmail: requireVerifiedEmail, deletePolicy: deletePolicy, events: events}
+}
+
+func (s *PhotoService) FindAll(ctx context.Context) ([]core.Photo, error) {
+	ctx, span := tracer.Start(ctx, "PhotoService.FindAll")
+	defer span.End()
+
+	return s.photos.FindAll(ctx)
+}
+
+func (s *PhotoService) FindByID(ctx context.Context, id int64) (core.Photo, error) {
+	ctx, span := tracer.Start(ctx, "PhotoService.FindByID")
+	defer span.End()
+
+	return s.photos.FindByID(ctx, id)
+}
+
+func (s *PhotoService) FindByPublicID(ctx context.Context, publicID string) (core.Photo, error) {
+	ctx, span := tracer.Start(ctx, "PhotoService.FindByPublicID")
+	defer span.End()
+
+	return s.photos.FindByPublicID(ctx, publicID)
+}
+
+// Create stores a photo owned by owner, enforcing the email verification policy.
+func (s *PhotoService) Create(ctx context.Context, owner core.User, photo *core.Photo) error {
+	ctx, span := tracer.Start(ctx, "PhotoService.Create")
+	defer span.End()
+
+	if s.requireVerifiedEmail && !owner.EmailVerified() {
+		return ErrEmailNotVerified
+	}
+
+	photo.UserID = owner.ID
+	if err := s.photos.Create(ctx, photo); err != nil {
+		return err
+	}
+	photo.User = &owner
+	s.events.Record(ctx, EventPhotoCreated)
+	return nil
+}
+
+func (s *PhotoService) Update(ctx context.Context, photo *core.Photo) error {
+	ctx, span := tracer.Start(ctx, "PhotoService.Update")
+	defer span.End()
+
+	return s.photos.Update(ctx, photo)
+}
+
+// Delete removes the photo, cascading to its comments or refusing while it has any,
+// depending on the delete policy.
+func (s *PhotoService) Delete(ctx context.Context, photo *core.Photo) error {
+	ctx, span := tracer.Start(ctx, "PhotoService.Delete")
+	defer span.End()
+
+	return deleteWithPolicy[core.Photo](ctx, s.photos, s.deletePolicy, photo.ID, photo)
+}
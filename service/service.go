@@ -0,0 +1,73 @@
+package service
+
+import (
+	"finalproject/helpers"
+	"finalproject/mailer"
+	"finalproject/repository"
+
+	"go.opentelemetry.io/otel"
+)
+
+// tracer opens a span around every service call, so traces show the business step behind each query.
+var tracer = otel.Tracer("finalproject/service")
+
+// SigningKeyRetention is how long a retired signing key keeps verifying: the longest lifetime of any token
+// signed by the key ring, so rotating never breaks an access token, verification link or 2FA challenge early.
+const SigningKeyRetention = max(helpers.AccessTokenTTL, EmailVerificationTTL, TwoFactorChallengeTTL)
+
+// Dependencies are the infrastructure pieces shared by the services.
+type Dependencies struct {
+	Keys      *helpers.KeyRing
+	Passwords *helpers.Passwords
+	Mailer    mailer.Mailer
+	AppURL    string // Base URL of the frontend, used in links sent by email
+
+	// RequireVerifiedEmailForPhotos blocks posting photos until the user confirmed their email.
+	RequireVerifiedEmailForPhotos bool
+	// Lockout tunes brute-force protection; zero fields use DefaultLockoutPolicy.
+	Lockout LockoutPolicy
+	// DeletePolicy applies to users and photos; empty means DeleteCascade.
+	DeletePolicy DeletePolicy
+	// Events observes business events; nil discards them.
+	Events EventRecorder
+}
+
+// Services groups every service built on top of one set of repositories.
+type Services struct {
+	Users         *UserService
+	Photos        *PhotoService
+	Comments      *CommentService
+	SocialMedia   *SocialMediaService
+	Auth          *AuthService
+	PasswordReset *PasswordResetService
+
+	EmailVerification *EmailVerificationService
+	TwoFactor         *TwoFactorService
+	LoginGuard        *LoginGuard
+}
+
+func New(repos repository.Repositories, deps Dependencies) Services {
+	events := deps.Events
+	if events == nil {
+		events = noEvents{}
+	}
+	auth := NewAuthService(repos.Users, repos.RefreshTokens, deps.Keys, events)
+
+	deletePolicy := deps.DeletePolicy
+	if deletePolicy == "" {
+		deletePolicy = DeleteCascade
+	}
+
+	return Services{
+		Users:         NewUserService(repos.Users, deps.Passwords, deletePolicy, events),
+		Photos:        NewPhotoService(repos.Photos, deps.RequireVerifiedEmailForPhotos, deletePolicy, events),
+		Comments:      NewCommentService(repos.Comments, repos.Photos, events),
+		SocialMedia:   NewSocialMediaService(repos.SocialMedia),
+		Auth:          auth,
+		PasswordReset: NewPasswordResetService(repos.Users, repos.PasswordResetTokens, deps.Passwords, auth, deps.Mailer, deps.AppURL),
+
+		EmailVerification: NewEmailVerificationService(repos.Users, deps.Keys, deps.Mailer, deps.AppURL),
+		TwoFactor:         NewTwoFactorService(repos.Users, repos.RecoveryCodes, repos.TwoFactorChallenges, deps.Keys, deps.Passwords, auth),
+		LoginGuard:        NewLoginGuard(repos.LoginAttempts, repos.Users, deps.Mailer, deps.Lockout, events),
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"finalproject/core"
+	"finalproject/repository"
+)
+
+type SocialMediaService struct {
+	socialMedia repository.SocialMediaRepository
+}
+
+func NewSocialMediaService(socialMedia repository.SocialMediaRepository) *SocialMediaService {
+	return &SocialMediaService{socialMedia: socialMedia}
+}
+
+func (s *SocialMediaService) FindAll(ctx context.Context) ([]core.SocialMedia, error) {
+	ctx, span := tracer.Start(ctx, "SocialMediaService.FindAll")
+	defer span.End()
+
+	return s.socialMedia.FindAll(ctx)
+}
+
+func (s *SocialMediaService) FindByID(ctx context.Context, id int64) (core.SocialMedia, error) {
+	ctx, span := tracer.Start(ctx, "SocialMediaService.FindByID")
+	defer span.End()
+
+	return s.socialMedia.FindByID(ctx, id)
+}
+
+func (s *SocialMediaService) FindByPublicID(ctx context.Context, publicID string) (core.SocialMedia, error) {
+	ctx, span := tracer.Start(ctx, "SocialMediaService.FindByPublicID")
+	defer span.End()
+
+	return s.socialMedia.FindByPublicID(ctx, publicID)
+}
+
+func (s *SocialMediaService) Create(ctx context.Context, socialMedia *core.SocialMedia) error {
+	ctx, span := tracer.Start(ctx, "SocialMediaService.Create")
+	defer span.End()
+
+	return s.socialMedia.Create(ctx, socialMedia)
+}
+
+func (s *SocialMediaService) Update(ctx context.Context, socialMedia *core.SocialMedia) error {
+	ctx, span := tracer.Start(ctx, "SocialMediaService.Update")
+	defer span.End()
+
+	return s.socialMedia.Update(ctx, socialMedia)
+}
+
+func (s *SocialMediaService) Delete(ctx context.Context, socialMedia *core.SocialMedia) error {
+	ctx, span := tracer.Start(ctx, "SocialMediaService.Delete")
+	defer span.End()
+
+	return s.socialMedia.Delete(ctx, socialMedia)
+}
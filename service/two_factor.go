@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"finalproject/core"
+	"finalproject/helpers"
+	"finalproject/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// TwoFactorChallengeTTL is how long the second login step may take.
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// TwoFactorChallengeAttempts is how many wrong codes burn a challenge, sending the user back to the password step.
+	TwoFactorChallengeAttempts = 5
+	// RecoveryCodeCount is how many recovery codes are generated at a time.
+	RecoveryCodeCount = 10
+
+	twoFactorChallengeAudience = "mygram-2fa-challenge"
+	totpIssuer                 = "mygram"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication not enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor enrollment not started")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidChallenge        = errors.New("invalid or expired two-factor challenge")
+)
+
+// Enrollment is what an authenticator app needs to start generating codes.
+type Enrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+	QRPayload  string `json:"qrPayload"` // Text to encode in the QR code shown to the user
+}
+
+type twoFactorChallengeClaims struct {
+	UserID string `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+type TwoFactorService struct {
+	users         repository.UserRepository
+	recoveryCodes repository.RecoveryCodeRepository
+	challenges    repository.TwoFactorChallengeRepository
+	keys          *helpers.KeyRing
+	passwords     *helpers.Passwords // Hashes recovery codes like passwords, so a leaked table cannot be brute-forced quickly
+	auth          *AuthService
+}
+
+func NewTwoFactorService(users repository.UserRepository, recoveryCodes repository.RecoveryCodeRepository, challenges repository.TwoFactorChallengeRepository, keys *helpers.KeyRing, passwords *helpers.Passwords, auth *AuthService) *TwoFactorService {
+	return &TwoFactorService{users: users, recoveryCodes: recoveryCodes, challenges: challenges, keys: keys, passwords: passwords, auth: auth}
+}
+
+// Enroll generates a new TOTP secret. It only takes effect once Confirm receives a valid code.
+func (s *TwoFactorService) Enroll(ctx context.Context, userID int64) (Enrollment, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.Enroll")
+	defer span.End()
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return Enrollment{}, err
+	}
+	if user.TwoFactorEnabled() {
+		return Enrollment{}, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		return Enrollment{}, err
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if err := s.users.Update(ctx, &user); err != nil {
+		return Enrollment{}, err
+	}
+
+	uri := helpers.TOTPURI(totpIssuer, user.Email, secret)
+	return Enrollment{Secret: secret, OTPAuthURI: uri, QRPayload: uri}, nil
+}
+
+// Confirm enables two-factor authentication and returns the first set of recovery codes.
+func (s *TwoFactorService) Confirm(ctx context.Context, userID int64, code string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.Confirm")
+	defer span.End()
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	if err := s.acceptTOTP(ctx, &user, code); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	if err := s.users.Update(ctx, &user); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(ctx, user.ID)
+}
+
+// Disable turns two-factor authentication off after checking a TOTP or recovery code.
+func (s *TwoFactorService) Disable(ctx context.Context, userID int64, code string) error {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.Disable")
+	defer span.End()
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := s.verify(ctx, &user, code); err != nil {
+		return err
+	}
+
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.TOTPLastStep = 0
+	if err := s.users.Update(ctx, &user); err != nil {
+		return err
+	}
+	return s.recoveryCodes.DeleteByUser(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes invalidates the old recovery codes after checking a TOTP code.
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.RegenerateRecoveryCodes")
+	defer span.End()
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err := s.acceptTOTP(ctx, &user, code); err != nil {
+		return nil, err
+	}
+	if err := s.users.Update(ctx, &user); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(ctx, user.ID)
+}
+
+// Challenge returns the short-lived token LoginUser hands out instead of a session when 2FA is on.
+// Its jti is recorded, so the token can be completed once and burnt after too many wrong codes.
+func (s *TwoFactorService) Challenge(ctx context.Context, user core.User) (string, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.Challenge")
+	defer span.End()
+
+	jti, err := helpers.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	challenge := core.TwoFactorChallenge{
+		UserID:    user.ID,
+		TokenHash: helpers.HashToken(jti),
+		ExpiresAt: now.Add(TwoFactorChallengeTTL),
+	}
+	if err := s.challenges.Create(ctx, &challenge); err != nil {
+		return "", err
+	}
+
+	return s.keys.Sign(twoFactorChallengeClaims{
+		UserID: user.PublicID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    helpers.TokenIssuer,
+			Audience:  jwt.ClaimStrings{twoFactorChallengeAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(challenge.ExpiresAt),
+		},
+	})
+}
+
+// ChallengeUser returns the account a challenge token was issued for, so the caller can throttle guesses
+// per account before the code is checked.
+func (s *TwoFactorService) ChallengeUser(ctx context.Context, challenge string) (core.User, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.ChallengeUser")
+	defer span.End()
+
+	var claims twoFactorChallengeClaims
+	if err := s.keys.Parse(challenge, &claims, jwt.WithAudience(twoFactorChallengeAudience)); err != nil {
+		return core.User{}, ErrInvalidChallenge
+	}
+	user, err := s.users.FindByPublicID(ctx, claims.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return core.User{}, ErrInvalidChallenge
+	}
+	return user, err
+}
+
+// CompleteChallenge exchanges a challenge token plus a TOTP or recovery code for a session.
+func (s *TwoFactorService) CompleteChallenge(ctx context.Context, challenge, code string, client ClientInfo) (TokenPair, error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.CompleteChallenge")
+	defer span.End()
+
+	// 1. The token must be genuine and its recorded challenge neither used nor burnt
+	var claims twoFactorChallengeClaims
+	if err := s.keys.Parse(challenge, &claims, jwt.WithAudience(twoFactorChallengeAudience)); err != nil || claims.ID == "" {
+		return TokenPair{}, ErrInvalidChallenge
+	}
+	record, err := s.challenges.FindByHash(ctx, helpers.HashToken(claims.ID))
+	if errors.Is(err, repository.ErrNotFound) {
+		return TokenPair{}, ErrInvalidChallenge
+	} else if err != nil {
+		return TokenPair{}, err
+	}
+	now := time.Now()
+	if !record.Active(now) {
+		return TokenPair{}, ErrInvalidChallenge
+	}
+
+	user, err := s.users.FindByPublicID(ctx, claims.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return TokenPair{}, ErrInvalidChallenge
+	} else if err != nil {
+		return TokenPair{}, err
+	}
+	if user.ID != record.UserID || !user.TwoFactorEnabled() {
+		return TokenPair{}, ErrInvalidChallenge
+	}
+
+	// 2. Check the code; every wrong one counts against the challenge
+	if err := s.verify(ctx, &user, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if err := s.challenges.RecordFailure(ctx, record.ID, TwoFactorChallengeAttempts, now); err != nil {
+				return TokenPair{}, err
+			}
+		}
+		return TokenPair{}, err
+	}
+
+	// 3. Consume the challenge, so it cannot be completed a second time
+	if err := s.challenges.MarkUsed(ctx, record.ID, now); errors.Is(err, repository.ErrNotFound) {
+		return TokenPair{}, ErrInvalidChallenge
+	} else if err != nil {
+		return TokenPair{}, err
+	}
+	return s.auth.IssueTokens(ctx, user, client)
+}
+
+// verify accepts either a TOTP code or an unused recovery code, persisting the TOTP step or spent code.
+func (s *TwoFactorService) verify(ctx context.Context, user *core.User, code string) error {
+	if err := s.acceptTOTP(ctx, user, code); err == nil {
+		return s.users.Update(ctx, user)
+	}
+
+	normalized := helpers.NormalizeRecoveryCode(code)
+	if len(normalized) != helpers.RecoveryCodeLength {
+		return ErrInvalidTwoFactorCode
+	}
+	codes, err := s.recoveryCodes.FindUnusedByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// Every candidate is checked, so the time taken does not reveal which code matched
+	var match *core.RecoveryCode
+	for i := range codes {
+		ok, _, err := s.passwords.Verify(codes[i].CodeHash, normalized)
+		if err != nil && !errors.Is(err, helpers.ErrUnknownHashFormat) {
+			return err
+		}
+		if ok && match == nil {
+			match = &codes[i]
+		}
+	}
+	if match == nil {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if err := s.recoveryCodes.MarkUsed(ctx, match.ID, time.Now()); errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidTwoFactorCode
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// acceptTOTP validates code and advances the user's last step; the caller saves the user.
+func (s *TwoFactorService) acceptTOTP(ctx context.Context, user *core.User, code string) error {
+	step, ok := helpers.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return ErrInvalidTwoFactorCode
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+func (s *TwoFactorService) replaceRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	plain := make([]string, 0, RecoveryCodeCount)
+	codes := make([]core.RecoveryCode, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := helpers.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		hash, err := s.passwords.Hash(helpers.NormalizeRecoveryCode(code))
+		if err != nil {
+			return nil, err
+		}
+		plain = append(plain, code)
+		codes = append(codes, core.RecoveryCode{CodeHash: hash})
+	}
+
+	if err := s.recoveryCodes.Replace(ctx, userID, codes); err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"finalproject/core"
+	"finalproject/helpers"
+	"finalproject/repository"
+)
+
+var (
+	// ErrInvalidCredentials is returned by Authenticate for an unknown email or a wrong password alike.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidRole        = errors.New("invalid role")
+	// ErrLastAdmin prevents demoting or deleting the only remaining admin.
+	ErrLastAdmin = errors.New("cannot remove the last admin")
+)
+
+type UserService struct {
+	users        repository.UserRepository
+	passwords    *helpers.Passwords
+	deletePolicy DeletePolicy
+	events       EventRecorder
+
+	dummyOnce sync.Once
+	dummyHash string
+}
+
+func NewUserService(users repository.UserRepository, passwords *helpers.Passwords, deletePolicy DeletePolicy, events EventRecorder) *UserService {
+	return &UserService{users: users, passwords: passwords, deletePolicy: deletePolicy, events: events}
+}
+
+// Register hashes the password and creates the user unless the email is already in use.
+func (s *UserService) Register(ctx context.Context, user *core.User) error {
+	ctx, span := tracer.Start(ctx, "UserService.Register")
+	defer span.End()
+
+	_, err := s.users.FindByEmail(ctx, user.Email)
+	if err == nil {
+		return ErrEmailTaken
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	// New accounts always start as unverified regular users, whatever the request said
+	user.EmailVerifiedAt = nil
+	user.PendingEmail = ""
+	user.Role = core.RoleUser
+
+	user.Password, err = s.passwords.Hash(user.Password)
+	if err != nil {
+		return err
+	}
+
+	err = s.users.Create(ctx, user)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return ErrEmailTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	s.events.Record(ctx, EventRegistered)
+	return nil
+}
+
+// Authenticate checks the credentials and, on success, upgrades the stored hash when it uses a legacy
+// algorithm or outdated parameters.
+func (s *UserService) Authenticate(ctx context.Context, email, password string) (core.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.Authenticate")
+	defer span.End()
+
+	user, err := s.users.FindByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Spend the same time as a real comparison so response times do not reveal which emails exist
+		s.dummyOnce.Do(func() { s.dummyHash, _ = s.passwords.Hash("dummy password") })
+		s.passwords.Verify(s.dummyHash, password)
+		return core.User{}, ErrInvalidCredentials
+	} else if err != nil {
+		return core.User{}, err
+	}
+
+	ok, rehash, err := s.passwords.Verify(user.Password, password)
+	if err != nil && !errors.Is(err, helpers.ErrUnknownHashFormat) {
+		return core.User{}, err
+	}
+	if !ok {
+		return core.User{}, ErrInvalidCredentials
+	}
+
+	if rehash {
+		// Failing to upgrade the hash must not fail the login; the next one will retry
+		hashed, err := s.passwords.Hash(password)
+		if err == nil {
+			user.Password = hashed
+			err = s.users.Update(ctx, &user)
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "failed to upgrade password hash", "user_id", user.PublicID, "error", err)
+		}
+	}
+
+	return user, nil
+}
+
+// BootstrapAdmin promotes the account with the given email to admin, creating it with the given
+// username, age and password when it does not exist yet. Accounts created this way count as verified.
+func (s *UserService) BootstrapAdmin(ctx context.Context, user core.User) (created bool, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.BootstrapAdmin")
+	defer span.End()
+
+	existing, err := s.users.FindByEmail(ctx, user.Email)
+	if err == nil {
+		existing.Role = core.RoleAdmin
+		return false, s.users.Update(ctx, &existing)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return false, err
+	}
+
+	if err := user.Validate(); err != nil {
+		return false, err
+	}
+	user.Password, err = s.passwords.Hash(user.Password)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	user.Role = core.RoleAdmin
+	user.EmailVerifiedAt = &now
+	user.PendingEmail = ""
+	if err := s.users.Create(ctx, &user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return false, ErrEmailTaken
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// SetRole changes the role of a user, refusing to demote the last admin.
+func (s *UserService) SetRole(ctx context.Context, publicID string, role core.Role) (core.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.SetRole")
+	defer span.End()
+
+	if !role.Valid() {
+		return core.User{}, ErrInvalidRole
+	}
+
+	user, err := s.users.FindByPublicID(ctx, publicID)
+	if err != nil {
+		return core.User{}, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	if user.Role == core.RoleAdmin {
+		if err := s.ensureAnotherAdmin(ctx, user.ID); err != nil {
+			return core.User{}, err
+		}
+	}
+
+	user.Role = role
+	if err := s.users.Update(ctx, &user); err != nil {
+		return core.User{}, err
+	}
+	// Access tokens already issued keep the old role claim until they expire
+	return user, nil
+}
+
+// ensureAnotherAdmin returns ErrLastAdmin unless an admin other than userID exists.
+func (s *UserService) ensureAnotherAdmin(ctx context.Context, userID int64) error {
+	users, err := s.users.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.Role == core.RoleAdmin && user.ID != userID {
+			return nil
+		}
+	}
+	return ErrLastAdmin
+}
+
+func (s *UserService) FindAll(ctx context.Context) ([]core.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.FindAll")
+	defer span.End()
+
+	return s.users.FindAll(ctx)
+}
+
+func (s *UserService) FindByID(ctx context.Context, id int64) (core.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.FindByID")
+	defer span.End()
+
+	return s.users.FindByID(ctx, id)
+}
+
+func (s *UserService) FindByPublicID(ctx context.Context, publicID string) (core.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.FindByPublicID")
+	defer span.End()
+
+	return s.users.FindByPublicID(ctx, publicID)
+}
+
+func (s *UserService) FindByEmail(ctx context.Context, email string) (core.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.FindByEmail")
+	defer span.End()
+
+	return s.users.FindByEmail(ctx, email)
+}
+
+func (s *UserService) Update(ctx context.Context, user *core.User) error {
+	ctx, span := tracer.Start(ctx, "UserService.Update")
+	defer span.End()
+
+	err := s.users.Update(ctx, user)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return s.duplicateError(ctx, user)
+	}
+	return err
+}
+
+// duplicateError tells which unique column a write of user collided on: the email when another
+// account holds it, the username otherwise.
+func (s *UserService) duplicateError(ctx context.Context, user *core.User) error {
+	existing, err := s.users.FindByEmail(ctx, user.Email)
+	if err == nil && existing.ID != user.ID {
+		return ErrEmailTaken
+	}
+	return ErrUsernameTaken
+}
+
+// Delete removes the user, cascading to their content or refusing while they have any,
+// depending on the delete policy.
+func (s *UserService) Delete(ctx context.Context, user *core.User) error {
+	ctx, span := tracer.Start(ctx, "UserService.Delete")
+	defer span.End()
+
+	if user.Role == core.RoleAdmin {
+		if err := s.ensureAnotherAdmin(ctx, user.ID); err != nil {
+			return err
+		}
+	}
+	return deleteWithPolicy[core.User](ctx, s.users, s.deletePolicy, user.ID, user)
+}
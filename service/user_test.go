@@ -0,0 +1,41 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"finalproject/core"
+	"finalproject/helpers"
+	"finalproject/repository/memory"
+	"finalproject/service"
+)
+
+func TestUpdateReportsWhichColumnIsTaken(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	passwords, err := helpers.NewPasswords("bcrypt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := service.New(repos, service.Dependencies{Passwords: passwords}).Users
+
+	jane := core.User{Username: "jane", Email: "jane@example.com", Password: "hash", Age: 20}
+	john := core.User{Username: "john", Email: "john@example.com", Password: "hash", Age: 20}
+	for _, user := range []*core.User{&jane, &john} {
+		if err := repos.Users.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taken := john
+	taken.Email = jane.Email
+	if err := users.Update(ctx, &taken); !errors.Is(err, service.ErrEmailTaken) {
+		t.Errorf("update to a taken email: got %v, want %v", err, service.ErrEmailTaken)
+	}
+	taken = john
+	taken.Username = jane.Username
+	if err := users.Update(ctx, &taken); !errors.Is(err, service.ErrUsernameTaken) {
+		t.Errorf("update to a taken username: got %v, want %v", err, service.ErrUsernameTaken)
+	}
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"errors"
+	"regexp"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// stringLiteral matches quoted SQL strings, which sanitize replaces with a placeholder.
+var stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// ObserveDatabase records a client span for every query made through db. The span carries the SQL
+// with placeholders, never the arguments, and the context of the query, so it nests under the request.
+func ObserveDatabase(db *gorm.DB) error {
+	before := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			name := operation
+			if tx.Statement.Table != "" {
+				name += " " + tx.Statement.Table
+			}
+			_, span := tracer.Start(tx.Statement.Context, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperation(operation)),
+			)
+			tx.InstanceSet(spanKey, span)
+		}
+	}
+	after := func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(spanKey)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		defer span.End()
+
+		span.SetAttributes(
+			semconv.DBStatement(sanitize(tx.Statement.SQL.String())),
+			attribute.Int64("db.rows_affected", tx.RowsAffected),
+		)
+		if tx.Statement.Table != "" {
+			span.SetAttributes(semconv.DBSQLTable(tx.Statement.Table))
+		}
+		// A missing row is an answer, not a failure
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(tx.Error)
+			span.SetStatus(codes.Error, tx.Error.Error())
+		}
+	}
+
+	// Each processor runs its statement in the gorm:<operation> callback; the span wraps it
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", before("INSERT")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", after),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", before("SELECT")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", after),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", before("UPDATE")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", after),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", before("DELETE")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", after),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", before("ROW")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", after),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", before("RAW")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", after),
+	)
+}
+
+// sanitize removes the string literals that hand-written SQL may embed; arguments are already placeholders.
+func sanitize(sql string) string {
+	return stringLiteral.ReplaceAllString(sql, "'?'")
+}
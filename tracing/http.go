@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"net/http"
+
+	"finalproject/core"
+	"finalproject/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span for every request, continuing the trace of an incoming traceparent
+// header. It must run after middleware.RequestID, whose ID is recorded on the span.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. Continue the caller's trace, if any
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		// 2. Name the span after the route template, so spans of one endpoint group together
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				attribute.String("request_id", middleware.GetRequestID(c)),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		// 3. Record the outcome; only server errors mark the span as failed
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if user, ok := c.Get(middleware.CurrentUserKey); ok {
+			span.SetAttributes(semconv.EnduserID(user.(core.User).PublicID))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+			if len(c.Errors) > 0 {
+				span.RecordError(c.Errors.Last().Err)
+			}
+		}
+	}
+}
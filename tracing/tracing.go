@@ -0,0 +1,80 @@
+// Package tracing sets up OpenTelemetry: the tracer provider and its exporter, W3C trace context
+// propagation, server spans for HTTP requests and client spans for database queries.
+// Spans are created through the global provider, so they cost next to nothing while tracing is off.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"finalproject/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+const instrumentation = "finalproject"
+
+var tracer = otel.Tracer(instrumentation)
+
+// memory keeps the spans of the memory exporter in the process.
+var memory = tracetest.NewInMemoryExporter()
+
+// Spans returns the spans recorded by the memory exporter since Setup, oldest first.
+func Spans() tracetest.SpanStubs {
+	return memory.GetSpans()
+}
+
+// Setup installs the global tracer provider for the configured exporter: none, stdout (for local use),
+// memory (for tests, read back with Spans) or otlp. The returned function flushes buffered spans and must be called before the process exits.
+func Setup(ctx context.Context, cfg config.TracingConfig) (shutdown func(context.Context) error, err error) {
+	// Propagation is installed even without an exporter, so incoming trace context still reaches the logs
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New()
+	case "memory":
+		memory.Reset()
+		exporter = memory
+	case "otlp":
+		var options []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s exporter: %w", cfg.Exporter, err)
+	}
+
+	provider := NewProvider(cfg, exporter)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider returns a provider batching spans to exporter. A tracetest.InMemoryExporter is written to
+// synchronously instead, so its spans can be read as soon as they end.
+func NewProvider(cfg config.TracingConfig, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	processor := sdktrace.NewBatchSpanProcessor(exporter)
+	if _, ok := exporter.(*tracetest.InMemoryExporter); ok {
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))),
+		// Traces sampled upstream are always continued, so a trace is never cut in half
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+}
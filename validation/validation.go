@@ -0,0 +1,144 @@
+// Package validation checks structs against their `validate` tags and reports every failing
+// field at once. Besides the go-playground/validator built-ins it provides the rules
+// weburl (absolute http or https URL), username (3-30 letters, digits, dots or underscores)
+// and age (13 to 120).
+package validation
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"finalproject/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	MinAge = 13
+	MaxAge = 120
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.]{3,30}$`)
+
+// FieldError describes one failing field. Field is the JSON name and Code the failing rule;
+// Message is rendered in i18n.DefaultLocale until the list is localized.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	messageCode string
+	args        []any
+}
+
+// Errors lists every failing field of a struct.
+type Errors []FieldError
+
+// Localize returns a copy of the errors with messages rendered in locale.
+func (e Errors) Localize(locale i18n.Locale) Errors {
+	localized := make(Errors, len(e))
+	for i, fieldError := range e {
+		fieldError.Message = i18n.T(locale, fieldError.messageCode, fieldError.args...)
+		localized[i] = fieldError
+	}
+	return localized
+}
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		messages = append(messages, fieldError.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+var (
+	once     sync.Once
+	validate *validator.Validate
+)
+
+func instance() *validator.Validate {
+	once.Do(func() {
+		validate = validator.New()
+
+		// Report fields by their JSON name, which is what clients sent; fields hidden from JSON
+		// (such as the password) fall back to the lower-cased Go name
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || name == "" {
+				return strings.ToLower(field.Name[:1]) + field.Name[1:]
+			}
+			return name
+		})
+
+		validate.RegisterValidation("weburl", func(fl validator.FieldLevel) bool {
+			parsed, err := url.Parse(fl.Field().String())
+			return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+		})
+		validate.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+			return usernamePattern.MatchString(fl.Field().String())
+		})
+		validate.RegisterValidation("age", func(fl validator.FieldLevel) bool {
+			age := fl.Field().Int()
+			return age >= MinAge && age <= MaxAge
+		})
+	})
+	return validate
+}
+
+// Struct validates s and returns Errors when any field fails.
+func Struct(s any) error {
+	return convert(instance().Struct(s), "")
+}
+
+// Var validates a single value reported under the given field name.
+func Var(field string, value any, tag string) error {
+	return convert(instance().Var(value, tag), field)
+}
+
+func convert(err error, field string) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	result := make(Errors, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		name := field
+		if name == "" {
+			name = fieldError.Field()
+		}
+		messageCode, args := message(name, fieldError)
+		result = append(result, FieldError{
+			Field:       name,
+			Code:        fieldError.Tag(),
+			Message:     i18n.T(i18n.DefaultLocale, messageCode, args...),
+			messageCode: messageCode,
+			args:        args,
+		})
+	}
+	return result
+}
+
+// message returns the catalog code and arguments describing the failed rule.
+func message(field string, fieldError validator.FieldError) (string, []any) {
+	switch tag := fieldError.Tag(); tag {
+	case "required", "email", "weburl", "username":
+		return "validation." + tag, []any{field}
+	case "min", "max":
+		if fieldError.Kind() == reflect.String {
+			return "validation." + tag + "_len", []any{field, fieldError.Param()}
+		}
+		return "validation." + tag, []any{field, fieldError.Param()}
+	case "oneof":
+		return "validation.oneof", []any{field, strings.ReplaceAll(fieldError.Param(), " ", ", ")}
+	case "age":
+		return "validation.age", []any{field, MinAge, MaxAge}
+	default:
+		return "validation.invalid", []any{field}
+	}
+}